@@ -0,0 +1,263 @@
+package assist
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+/*
+ Encrypt values with key rotation
+
+ key, _ := assist.GenerateAEADKey("2024-01", assist.XChaCha20Poly1305)
+ ring, _ := assist.NewKeyring(key, oldKey)
+
+ sealed, _ := ring.Encrypt([]byte("4111 1111 1111 1111"), []byte("cards.number"))
+ plain, _ := ring.Decrypt(sealed, []byte("cards.number"))
+*/
+
+// Ciphertext format, all numbers are single bytes:
+//
+//	version | cipher | key ID length | key ID | nonce | sealed data
+//
+// The header before the nonce is authenticated together with the additional data.
+const (
+	aeadVersion       = 1
+	aeadStreamVersion = 2
+)
+
+var (
+	ErrUnknownKey         = errors.New("aead: unknown key")
+	ErrDecrypt            = errors.New("aead: message authentication failed")
+	ErrMalformedEnvelope  = errors.New("aead: malformed ciphertext")
+	ErrUnsupportedVersion = errors.New("aead: unsupported ciphertext version")
+)
+
+// Cipher is an AEAD algorithm of the key.
+type Cipher byte
+
+const (
+	// AESGCM is AES-GCM with 12 byte random nonce, the key is 16, 24 or 32 bytes.
+	AESGCM Cipher = iota + 1
+	// XChaCha20Poly1305 is XChaCha20-Poly1305 with 24 byte random nonce, the key is 32 bytes.
+	XChaCha20Poly1305
+)
+
+func (c Cipher) String() string {
+	switch c {
+	case AESGCM:
+		return "AES-GCM"
+	case XChaCha20Poly1305:
+		return "XChaCha20-Poly1305"
+	}
+	return fmt.Sprintf("Cipher(%d)", int(c))
+}
+
+func (c Cipher) aead(key []byte) (cipher.AEAD, error) {
+	switch c {
+	case AESGCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case XChaCha20Poly1305:
+		return chacha20poly1305.NewX(key)
+	}
+	return nil, fmt.Errorf("aead: unknown cipher %v", c)
+}
+
+// AEADKey is a symmetric key identified by ID, the ID is stored in every ciphertext.
+type AEADKey struct {
+	ID     string
+	Cipher Cipher
+	key    []byte
+	aead   cipher.AEAD
+}
+
+// NewAEADKey checks the key size and the ID, which is 1 to 255 bytes long.
+func NewAEADKey(id string, c Cipher, key []byte) (*AEADKey, error) {
+	if len(id) == 0 || len(id) > 255 {
+		return nil, fmt.Errorf("aead: key ID must be 1 to 255 bytes long")
+	}
+	aead, err := c.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	return &AEADKey{ID: id, Cipher: c, key: append([]byte(nil), key...), aead: aead}, nil
+}
+
+// GenerateAEADKey generates the random 32 byte key, use Bytes to store it.
+func GenerateAEADKey(id string, c Cipher) (*AEADKey, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return NewAEADKey(id, c, key)
+}
+
+// Bytes returns the copy of the raw key, e.g. to keep the generated key in a secret store
+// and restore it with NewAEADKey.
+func (k *AEADKey) Bytes() []byte {
+	return append([]byte(nil), k.key...)
+}
+
+func (k *AEADKey) header(version byte) []byte {
+	h := make([]byte, 0, 3+len(k.ID))
+	h = append(h, version, byte(k.Cipher), byte(len(k.ID)))
+	return append(h, k.ID...)
+}
+
+// Keyring encrypts with the active key and decrypts with any key of the ring.
+type Keyring struct {
+	mu     sync.RWMutex
+	active *AEADKey
+	keys   map[string]*AEADKey
+}
+
+// NewKeyring returns the ring encrypting with the active key, other keys are used for decryption only.
+func NewKeyring(active *AEADKey, decryptOnly ...*AEADKey) (*Keyring, error) {
+	k := Keyring{keys: make(map[string]*AEADKey)}
+	for _, key := range decryptOnly {
+		if err := k.Add(key); err != nil {
+			return nil, err
+		}
+	}
+	if err := k.Rotate(active); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// Add appends the decrypt only key.
+func (k *Keyring) Add(key *AEADKey) error {
+	if key == nil {
+		return ErrUnknownKey
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if old, ok := k.keys[key.ID]; ok && old != key {
+		return fmt.Errorf("aead: duplicate key ID %q", key.ID)
+	}
+	k.keys[key.ID] = key
+	return nil
+}
+
+// Rotate makes the key active, the previous active key stays in the ring for decryption.
+func (k *Keyring) Rotate(key *AEADKey) error {
+	if err := k.Add(key); err != nil {
+		return err
+	}
+	k.mu.Lock()
+	k.active = key
+	k.mu.Unlock()
+	return nil
+}
+
+// Remove drops the decrypt only key, the active key can not be removed.
+func (k *Keyring) Remove(id string) {
+	k.mu.Lock()
+	if k.active == nil || k.active.ID != id {
+		delete(k.keys, id)
+	}
+	k.mu.Unlock()
+}
+
+// ActiveID returns ID of the key used for encryption.
+func (k *Keyring) ActiveID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.active.ID
+}
+
+// Encrypt seals the plaintext with the active key, aad is authenticated but not encrypted
+// and must be the same on decryption, e.g. the table and column name.
+func (k *Keyring) Encrypt(plaintext, aad []byte) ([]byte, error) {
+	k.mu.RLock()
+	key := k.active
+	k.mu.RUnlock()
+	header := key.header(aeadVersion)
+	nonce := make([]byte, key.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+key.aead.Overhead())
+	out = append(append(out, header...), nonce...)
+	return key.aead.Seal(out, nonce, plaintext, append(header, aad...)), nil
+}
+
+// Decrypt opens the ciphertext with the key it was encrypted with.
+func (k *Keyring) Decrypt(ciphertext, aad []byte) ([]byte, error) {
+	key, header, err := k.parseHeader(ciphertext, aeadVersion)
+	if err != nil {
+		return nil, err
+	}
+	rest := ciphertext[len(header):]
+	if len(rest) < key.aead.NonceSize()+key.aead.Overhead() {
+		return nil, ErrMalformedEnvelope
+	}
+	nonce, sealed := rest[:key.aead.NonceSize()], rest[key.aead.NonceSize():]
+	plain, err := key.aead.Open(nil, nonce, sealed, append(header[:len(header):len(header)], aad...))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plain, nil
+}
+
+// EncryptString encrypts the string and returns base64url encoded ciphertext, handy for text columns.
+func (k *Keyring) EncryptString(plaintext string, aad []byte) (string, error) {
+	out, err := k.Encrypt([]byte(plaintext), aad)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(out), nil
+}
+
+// DecryptString decrypts the string encrypted by EncryptString.
+func (k *Keyring) DecryptString(ciphertext string, aad []byte) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", ErrMalformedEnvelope
+	}
+	out, err := k.Decrypt(data, aad)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// KeyID returns ID of the key the ciphertext was encrypted with, e.g. to find values to re-encrypt
+// after rotation.
+func KeyID(ciphertext []byte) (string, error) {
+	if len(ciphertext) < 3 || len(ciphertext) < 3+int(ciphertext[2]) {
+		return "", ErrMalformedEnvelope
+	}
+	return string(ciphertext[3 : 3+int(ciphertext[2])]), nil
+}
+
+// parseHeader finds the key of the ciphertext and returns it with the header.
+func (k *Keyring) parseHeader(data []byte, version byte) (*AEADKey, []byte, error) {
+	if len(data) > 0 && data[0] != version {
+		return nil, nil, fmt.Errorf("%w : %d", ErrUnsupportedVersion, data[0])
+	}
+	id, err := KeyID(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	k.mu.RLock()
+	key, ok := k.keys[id]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("%w : %q", ErrUnknownKey, id)
+	}
+	if Cipher(data[1]) != key.Cipher {
+		return nil, nil, fmt.Errorf("%w : key %q is not %v", ErrMalformedEnvelope, id, Cipher(data[1]))
+	}
+	return key, data[:3+len(id)], nil
+}
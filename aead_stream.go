@@ -0,0 +1,241 @@
+package assist
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+/*
+ Encrypt large files
+
+ w, _ := ring.EncryptWriter(file, nil)
+ io.Copy(w, src)
+ w.Close()
+
+ r, _ := ring.DecryptReader(file, nil)
+ io.Copy(dst, r)
+*/
+
+// StreamSegmentSize is the size of plaintext sealed at once by the stream encryption.
+const StreamSegmentSize = 64 * 1024
+
+// ErrTruncated is returned when the encrypted stream ends before its last segment.
+var ErrTruncated = errors.New("aead: encrypted stream is truncated")
+
+// The stream is the header followed by segments, each segment is sealed separately:
+//
+//	version | cipher | key ID length | key ID | salt | nonce prefix | segment ...
+//
+// Segments are sealed with the key derived from the ring key and the random salt, so nonces
+// of different streams never meet under the same key. The nonce of a segment is the random prefix,
+// the big endian segment number and the flag of the last segment, so segments can not be reordered,
+// dropped or appended.
+const (
+	streamSaltSize    = 32
+	streamNonceSuffix = 5
+)
+
+// streamAEAD derives the key of the stream with HKDF-SHA256.
+func (k *AEADKey) streamAEAD(salt []byte) (cipher.AEAD, error) {
+	key := make([]byte, len(k.key))
+	if _, err := io.ReadFull(hkdf.New(sha256.New, k.key, salt, []byte("aead stream")), key); err != nil {
+		return nil, err
+	}
+	return k.Cipher.aead(key)
+}
+
+type streamWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	aad    []byte
+	prefix []byte
+	buf    []byte
+	seq    uint32
+	err    error
+}
+
+// EncryptWriter returns the writer which encrypts data written to it with the active key. Close must be
+// called to seal the last segment, it does not close the underlying writer.
+func (k *Keyring) EncryptWriter(w io.Writer, aad []byte) (io.WriteCloser, error) {
+	k.mu.RLock()
+	key := k.active
+	k.mu.RUnlock()
+	header := key.header(aeadStreamVersion)
+	random := make([]byte, streamSaltSize+key.aead.NonceSize()-streamNonceSuffix)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	salt, prefix := random[:streamSaltSize], random[streamSaltSize:]
+	sa, err := key.streamAEAD(salt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append(header, random...)); err != nil {
+		return nil, err
+	}
+	return &streamWriter{
+		w:      w,
+		aead:   sa,
+		aad:    append(header, aad...),
+		prefix: prefix,
+		buf:    make([]byte, 0, StreamSegmentSize),
+	}, nil
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	n := 0
+	for len(p) > 0 {
+		if len(s.buf) == StreamSegmentSize {
+			// the segment is sealed when more data comes, the last one is sealed by Close
+			if s.err = s.seal(false); s.err != nil {
+				return n, s.err
+			}
+		}
+		c := copy(s.buf[len(s.buf):StreamSegmentSize], p)
+		s.buf = s.buf[:len(s.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+func (s *streamWriter) Close() error {
+	if s.err != nil {
+		if s.err == io.ErrClosedPipe {
+			return nil
+		}
+		return s.err
+	}
+	if s.err = s.seal(true); s.err != nil {
+		return s.err
+	}
+	s.err = io.ErrClosedPipe
+	return nil
+}
+
+func (s *streamWriter) seal(last bool) error {
+	nonce := streamNonce(s.prefix, s.seq, last)
+	if s.seq++; s.seq == 0 {
+		return errors.New("aead: encrypted stream is too long")
+	}
+	_, err := s.w.Write(s.aead.Seal(nil, nonce, s.buf, s.aad))
+	s.buf = s.buf[:0]
+	return err
+}
+
+type streamReader struct {
+	r      *bufio.Reader
+	aead   cipher.AEAD
+	aad    []byte
+	prefix []byte
+	buf    []byte
+	out    []byte
+	plain  []byte
+	seq    uint32
+	done   bool
+	err    error
+}
+
+// DecryptReader returns the reader which decrypts the stream written by EncryptWriter. Data is
+// returned only after its segment is authenticated, ErrTruncated is returned when the stream
+// is cut short.
+func (k *Keyring) DecryptReader(r io.Reader, aad []byte) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(3)
+	if err != nil {
+		return nil, ErrMalformedEnvelope
+	}
+	if head, err = br.Peek(3 + int(head[2])); err != nil {
+		return nil, ErrMalformedEnvelope
+	}
+	key, header, err := k.parseHeader(head, aeadStreamVersion)
+	if err != nil {
+		return nil, err
+	}
+	header = append([]byte(nil), header...)
+	br.Discard(len(header))
+	random := make([]byte, streamSaltSize+key.aead.NonceSize()-streamNonceSuffix)
+	if _, err = io.ReadFull(br, random); err != nil {
+		return nil, ErrMalformedEnvelope
+	}
+	sa, err := key.streamAEAD(random[:streamSaltSize])
+	if err != nil {
+		return nil, err
+	}
+	return &streamReader{
+		r:      br,
+		aead:   sa,
+		aad:    append(header, aad...),
+		prefix: random[streamSaltSize:],
+		buf:    make([]byte, StreamSegmentSize+sa.Overhead()),
+		out:    make([]byte, 0, StreamSegmentSize),
+	}, nil
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	for len(s.plain) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		if s.done {
+			return 0, io.EOF
+		}
+		s.plain, s.err = s.open()
+	}
+	n := copy(p, s.plain)
+	s.plain = s.plain[n:]
+	return n, nil
+}
+
+// open reads and authenticates the next segment, the segment is the last one when the stream ends after it.
+func (s *streamReader) open() ([]byte, error) {
+	n, err := io.ReadFull(s.r, s.buf)
+	switch err {
+	case nil:
+		_, err = s.r.Peek(1)
+		s.done = err == io.EOF
+	case io.ErrUnexpectedEOF:
+		s.done = true
+	case io.EOF:
+		return nil, ErrTruncated
+	default:
+		return nil, err
+	}
+	if !s.done && err != nil {
+		return nil, err
+	}
+	nonce := streamNonce(s.prefix, s.seq, s.done)
+	s.seq++
+	plain, err := s.aead.Open(s.out[:0], nonce, s.buf[:n], s.aad)
+	if err != nil {
+		if !s.done {
+			return nil, ErrDecrypt
+		}
+		// the stream may be cut exactly at the segment boundary
+		if _, e := s.aead.Open(s.out[:0], streamNonce(s.prefix, s.seq-1, false), s.buf[:n], s.aad); e == nil {
+			return nil, ErrTruncated
+		}
+		return nil, ErrDecrypt
+	}
+	return plain, nil
+}
+
+func streamNonce(prefix []byte, seq uint32, last bool) []byte {
+	nonce := make([]byte, len(prefix)+streamNonceSuffix)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[len(prefix):], seq)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
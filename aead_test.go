@@ -0,0 +1,226 @@
+package assist
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+)
+
+func testKey(t *testing.T, id string, c Cipher) *AEADKey {
+	t.Helper()
+	key, err := GenerateAEADKey(id, c)
+	if err != nil {
+		t.Fatalf("fail to generate key : %v", err)
+	}
+	return key
+}
+
+func testRing(t *testing.T, active *AEADKey, decryptOnly ...*AEADKey) *Keyring {
+	t.Helper()
+	ring, err := NewKeyring(active, decryptOnly...)
+	if err != nil {
+		t.Fatalf("fail to create keyring : %v", err)
+	}
+	return ring
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	for _, c := range []Cipher{AESGCM, XChaCha20Poly1305} {
+		ring := testRing(t, testKey(t, "k1", c))
+		for _, plain := range [][]byte{nil, []byte("4111 1111 1111 1111"), bytes.Repeat([]byte{7}, 4096)} {
+			sealed, err := ring.Encrypt(plain, []byte("cards.number"))
+			if err != nil {
+				t.Fatalf("%v : fail to encrypt : %v", c, err)
+			}
+			if id, _ := KeyID(sealed); id != "k1" {
+				t.Fatalf("%v : expected key ID k1, got %q", c, id)
+			}
+			got, err := ring.Decrypt(sealed, []byte("cards.number"))
+			if err != nil || !bytes.Equal(got, plain) {
+				t.Fatalf("%v : round trip failed : %v", c, err)
+			}
+		}
+		s, err := ring.EncryptString("secret", nil)
+		if err != nil {
+			t.Fatalf("%v : fail to encrypt string : %v", c, err)
+		}
+		if got, err := ring.DecryptString(s, nil); err != nil || got != "secret" {
+			t.Fatalf("%v : string round trip failed : %q, %v", c, got, err)
+		}
+	}
+}
+
+func TestKeyringWrongAAD(t *testing.T) {
+	ring := testRing(t, testKey(t, "k1", XChaCha20Poly1305))
+	sealed, _ := ring.Encrypt([]byte("value"), []byte("users.email"))
+	if _, err := ring.Decrypt(sealed, []byte("users.phone")); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+	if _, err := ring.Decrypt(sealed, nil); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+	sealed[len(sealed)-1] ^= 1
+	if _, err := ring.Decrypt(sealed, []byte("users.email")); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt on tampered ciphertext, got %v", err)
+	}
+}
+
+func TestKeyringMalformed(t *testing.T) {
+	ring := testRing(t, testKey(t, "k1", AESGCM))
+	sealed, _ := ring.Encrypt([]byte("value"), nil)
+	for _, data := range [][]byte{nil, sealed[:2], sealed[:4], sealed[:10]} {
+		if _, err := ring.Decrypt(data, nil); !errors.Is(err, ErrMalformedEnvelope) {
+			t.Fatalf("expected ErrMalformedEnvelope for %d bytes, got %v", len(data), err)
+		}
+	}
+	sealed[0] = 9
+	if _, err := ring.Decrypt(sealed, nil); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	old, next := testKey(t, "2024-01", AESGCM), testKey(t, "2024-02", XChaCha20Poly1305)
+	ring := testRing(t, old)
+	before, _ := ring.Encrypt([]byte("value"), nil)
+
+	if err := ring.Rotate(next); err != nil {
+		t.Fatalf("fail to rotate : %v", err)
+	}
+	if ring.ActiveID() != "2024-02" {
+		t.Fatalf("expected active key 2024-02, got %s", ring.ActiveID())
+	}
+	after, _ := ring.Encrypt([]byte("value"), nil)
+	if id, _ := KeyID(after); id != "2024-02" {
+		t.Fatalf("expected new values to use 2024-02, got %s", id)
+	}
+	for _, sealed := range [][]byte{before, after} {
+		if got, err := ring.Decrypt(sealed, nil); err != nil || string(got) != "value" {
+			t.Fatalf("fail to decrypt after rotation : %v", err)
+		}
+	}
+
+	ring.Remove("2024-02")
+	if ring.ActiveID() != "2024-02" {
+		t.Fatal("the active key is removed")
+	}
+	ring.Remove("2024-01")
+	if _, err := ring.Decrypt(before, nil); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+	if err := ring.Add(testKey(t, "2024-02", AESGCM)); err == nil {
+		t.Fatal("duplicate key ID is accepted")
+	}
+}
+
+func TestGenerateAEADKeyBytes(t *testing.T) {
+	key := testKey(t, "k1", AESGCM)
+	raw := key.Bytes()
+	if len(raw) != 32 {
+		t.Fatalf("expected 32 byte key, got %d", len(raw))
+	}
+	raw[0] ^= 1
+	if bytes.Equal(raw, key.Bytes()) {
+		t.Fatal("Bytes returns the key itself")
+	}
+	restored, err := NewAEADKey("k1", AESGCM, key.Bytes())
+	if err != nil {
+		t.Fatalf("fail to restore key : %v", err)
+	}
+	sealed, _ := testRing(t, key).Encrypt([]byte("value"), nil)
+	if got, err := testRing(t, restored).Decrypt(sealed, nil); err != nil || string(got) != "value" {
+		t.Fatalf("restored key can not decrypt : %v", err)
+	}
+}
+
+func encryptStream(t *testing.T, ring *Keyring, plain, aad []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := ring.EncryptWriter(&buf, aad)
+	if err != nil {
+		t.Fatalf("fail to create writer : %v", err)
+	}
+	if _, err = w.Write(plain); err != nil {
+		t.Fatalf("fail to write : %v", err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("fail to close : %v", err)
+	}
+	return buf.Bytes()
+}
+
+func decryptStream(ring *Keyring, data, aad []byte) ([]byte, error) {
+	r, err := ring.DecryptReader(bytes.NewReader(data), aad)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	plain := make([]byte, 3*StreamSegmentSize+100)
+	rand.Read(plain)
+	for _, c := range []Cipher{AESGCM, XChaCha20Poly1305} {
+		ring := testRing(t, testKey(t, "k1", c))
+		for _, size := range []int{0, 1, StreamSegmentSize - 1, StreamSegmentSize, StreamSegmentSize + 1, len(plain)} {
+			got, err := decryptStream(ring, encryptStream(t, ring, plain[:size], []byte("file")), []byte("file"))
+			if err != nil || !bytes.Equal(got, plain[:size]) {
+				t.Fatalf("%v : round trip of %d bytes failed : %v", c, size, err)
+			}
+		}
+	}
+}
+
+func TestStreamUniquePerEncryption(t *testing.T) {
+	ring := testRing(t, testKey(t, "k1", AESGCM))
+	a := encryptStream(t, ring, []byte("value"), nil)
+	b := encryptStream(t, ring, []byte("value"), nil)
+	if bytes.Equal(a, b) {
+		t.Fatal("streams of the same data are equal")
+	}
+}
+
+func TestStreamWrongAAD(t *testing.T) {
+	ring := testRing(t, testKey(t, "k1", XChaCha20Poly1305))
+	data := encryptStream(t, ring, []byte("value"), []byte("a.bin"))
+	if _, err := decryptStream(ring, data, []byte("b.bin")); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt, got %v", err)
+	}
+}
+
+func TestStreamTamper(t *testing.T) {
+	key := testKey(t, "k1", AESGCM)
+	ring := testRing(t, key)
+	plain := make([]byte, 3*StreamSegmentSize+100)
+	data := encryptStream(t, ring, plain, nil)
+
+	head := len(key.header(aeadStreamVersion)) + streamSaltSize + key.aead.NonceSize() - streamNonceSuffix
+	segment := StreamSegmentSize + key.aead.Overhead()
+	seg := func(i int) []byte {
+		return data[head+i*segment : head+(i+1)*segment]
+	}
+	join := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"cut at segment boundary", data[:head+2*segment], ErrTruncated},
+		{"cut inside segment", data[:head+2*segment+10], ErrDecrypt},
+		{"header only", data[:head], ErrTruncated},
+		{"reordered", join(data[:head], seg(1), seg(0), data[head+2*segment:]), ErrDecrypt},
+		{"dropped", join(data[:head], seg(0), data[head+2*segment:]), ErrDecrypt},
+		{"appended", join(data, []byte{0}), ErrDecrypt},
+		{"short header", data[:head-1], ErrMalformedEnvelope},
+	}
+	for _, tt := range tests {
+		if _, err := decryptStream(ring, tt.data, nil); !errors.Is(err, tt.err) {
+			t.Errorf("%s : expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+}
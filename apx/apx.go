@@ -1,11 +1,17 @@
 package apx
 
 import (
+	"context"
+	"log"
 	"os"
 	"os/signal"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
+
+	assist "github.com/nooize/go-assist"
+	"github.com/nooize/go-assist/di"
 )
 
 /*
@@ -16,9 +22,14 @@ import (
     Add(db).
     Add(log).
     Add(api).
+    Main(serve).
     Run()
 */
 
+// MainFunc is the main thread of the application. The context is cancelled when the application is halted,
+// the function is expected to finish its work and return as soon as possible after that.
+type MainFunc func(ctx context.Context) error
+
 type Apx struct {
 	// Name application name
 	Name string
@@ -28,57 +39,110 @@ type Apx struct {
 	// InitTimeout limits the time to initialize resources.
 	// If the resources are not initialized within the allotted time, the application will not be launched
 	InitTimeout time.Duration
+	// StopTimeout limits the time for each unit to stop, the unit which does not stop in time is abandoned.
+	StopTimeout time.Duration
+	// ReadyInterval is the period of units readiness checks. A unit which reports an error from IsReady
+	// halts the application.
+	ReadyInterval time.Duration
+	// Signals the application listens to, the first signal halts the application, the second shuts it down.
+	// Signals reserved with assist.ReserveSignals are ignored, e.g. SIGHUP while env.Provider.ReloadOnSignal
+	// reloads the configuration on it.
+	// Clear the list to run the application without OS signals handling, e.g. in tests.
+	Signals []os.Signal
+	// Logger is passed to units and to the main function through the context, see LoggerFromContext.
+	Logger *log.Logger
 
 	startMu sync.Once
 	unitsMu sync.RWMutex
-	stop    chan os.Signal
+	state   int32
+	halt    chan struct{}
+	done    chan struct{}
+	ready   chan struct{}
 
-	units []*ApxUnit
+	main        MainFunc
+	units       []ApxUnit
+	listeners   []Listener
+	beforeStart []Hook
+	afterStop   []Hook
+	haltErr     error
 }
 
 func New(name string) *Apx {
 	s := Apx{
 		Name:             name,
-		stop:             make(chan os.Signal, 1),
-		units:            make([]*ApxUnit, 0),
+		Logger:           log.New(log.Writer(), "["+name+"] ", log.LstdFlags),
+		halt:             make(chan struct{}),
+		done:             make(chan struct{}),
+		ready:            make(chan struct{}),
+		Signals:          []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP},
+		units:            make([]ApxUnit, 0),
 		TerminateTimeout: time.Second * 3,
 		InitTimeout:      time.Second * 15,
+		StopTimeout:      di.DefaultStopTimeout,
+		ReadyInterval:    time.Second * 5,
 	}
 	return &s
 }
 
 // Add append dependency unit to application
-func (app *Apx) Add(unit *ApxUnit) *Apx {
-	if unit != nil {
+func (app *Apx) Add(unit ApxUnit) *Apx {
+	if unit == nil {
 		return app
 	}
 	app.unitsMu.Lock()
-	app.units = append(app.units, unit)
+	if atomic.LoadInt32(&app.state) == apxStateInit {
+		app.units = append(app.units, unit)
+	}
+	app.unitsMu.Unlock()
+	return app
+}
+
+// Main sets the main function of the application, it is started after all units are started.
+func (app *Apx) Main(fn MainFunc) *Apx {
+	app.unitsMu.Lock()
+	app.main = fn
 	app.unitsMu.Unlock()
-	// TODO check for started
 	return app
 }
 
 // Run method for start application
 func (app *Apx) Run() (err error) {
+	started := false
 	app.startMu.Do(func() {
+		started = true
 
 		stop := make(chan os.Signal, 1)
+		if len(app.Signals) > 0 {
+			signal.Notify(stop, app.Signals...)
+			defer signal.Stop(stop)
+		}
 
-		signal.Notify(stop, os.Interrupt, syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL, syscall.SIGHUP, syscall.SIGQUIT)
-
+		err = app.run(stop)
 	})
+	if !started {
+		return ErrAlreadyStarted
+	}
 	return
 }
 
 // Halt signals the application to terminate the current computational processes and prepare to stop the application.
+// A halt requested before Run is remembered, the application halts right after its units are started.
 func (app *Apx) Halt() {
-	if app.checkState(stateRunning, stateHalt) {
+	if app.checkState(stateRunning, stateHalt) || app.checkState(apxStateInit, stateHalt) {
 		close(app.halt)
 	}
 }
 
-// Shutdown stops the application immediately. At this point, all calculations should be completed.
+// Ready returns a channel which is closed when all units have reported readiness for the first time.
+func (app *Apx) Ready() <-chan struct{} {
+	return app.ready
+}
+
+// shutdownGrace is the time main is given to return after Shutdown, before ErrTermTimeout is reported.
+const shutdownGrace = 50 * time.Millisecond
+
+// Shutdown stops the application immediately. At this point, all calculations should be completed,
+// main which does not return right after its context is cancelled fails Run with ErrTermTimeout.
 func (app *Apx) Shutdown() {
 	app.Halt()
 	if app.checkState(stateHalt, stateShutdown) {
@@ -86,6 +150,246 @@ func (app *Apx) Shutdown() {
 	}
 }
 
+func (app *Apx) checkState(from, to int32) bool {
+	return atomic.CompareAndSwapInt32(&app.state, from, to)
+}
+
 func (app *Apx) run(sig <-chan os.Signal) (err error) {
 	defer app.Shutdown()
+
+	app.unitsMu.Lock()
+	// keeps the halt or shutdown requested before Run
+	app.checkState(apxStateInit, stateRunning)
+	units := append(make([]ApxUnit, 0, len(app.units)), app.units...)
+	main := app.main
+	beforeStart, afterStop := app.beforeStart, app.afterStop
+	app.unitsMu.Unlock()
+
+	ctx, cancel := context.WithCancel(app.context())
+	defer cancel()
+
+	if err = runHooks(ctx, beforeStart); err != nil {
+		return err
+	}
+	defer func() {
+		if hookErr := runHooks(app.context(), afterStop); err == nil {
+			err = hookErr
+		}
+	}()
+
+	graph, err := app.startUnits(ctx, units)
+	defer func() {
+		if stopErr := app.stopUnits(graph); err == nil {
+			err = stopErr
+		}
+	}()
+	if err != nil {
+		return err
+	}
+	select {
+	case <-app.halt:
+		// halted while units were starting, neither readiness checks nor main are needed
+		return app.haltError()
+	default:
+	}
+
+	var watchers sync.WaitGroup
+	watchers.Add(1)
+	go func() {
+		defer watchers.Done()
+		app.watchReady(ctx, units)
+	}()
+	defer watchers.Wait()
+	defer cancel()
+
+	result := make(chan error, 1)
+	if main != nil {
+		go func() {
+			result <- main(ctx)
+		}()
+	}
+
+	for {
+		select {
+		case err = <-result:
+			return err
+		case s := <-sig:
+			if assist.IsSignalReserved(s) {
+				continue
+			}
+			app.Logger.Printf("received signal %v, halting", s)
+			app.Halt()
+		case <-app.halt:
+		case <-app.done:
+		}
+		break
+	}
+	cancel()
+
+	if main == nil {
+		return app.haltError()
+	}
+	timer := time.NewTimer(app.TerminateTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case err = <-result:
+			if err == nil {
+				err = app.haltError()
+			}
+			return err
+		case s := <-sig:
+			if assist.IsSignalReserved(s) {
+				continue
+			}
+			app.Logger.Printf("received signal %v, shutting down", s)
+			app.Shutdown()
+		case <-app.done:
+			// the context is cancelled already, main has a moment to notice it
+			grace := time.NewTimer(shutdownGrace)
+			defer grace.Stop()
+			select {
+			case err = <-result:
+				if err == nil {
+					err = app.haltError()
+				}
+				return err
+			case <-grace.C:
+				return ErrTermTimeout
+			}
+		case <-timer.C:
+			return ErrTermTimeout
+		}
+	}
+}
+
+// haltWith halts the application and remembers the reason, it will be returned by Run.
+func (app *Apx) haltWith(err error) {
+	app.unitsMu.Lock()
+	if app.haltErr == nil {
+		app.haltErr = err
+	}
+	app.unitsMu.Unlock()
+	app.Halt()
+}
+
+func (app *Apx) haltError() error {
+	app.unitsMu.RLock()
+	defer app.unitsMu.RUnlock()
+	return app.haltErr
+}
+
+func runHooks(ctx context.Context, hooks []Hook) error {
+	for _, h := range hooks {
+		if err := h(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startUnits starts units one by one in the order they were added, by the runner shared with di.
+// Returns the graph of units even when one of the units fails, so the caller is able to stop
+// the started ones.
+func (app *Apx) startUnits(ctx context.Context, units []ApxUnit) (*di.Unit, error) {
+	initCtx, cancel := context.WithTimeout(ctx, app.InitTimeout)
+	defer cancel()
+	graph := app.graph(initCtx, units)
+	if graph == nil {
+		return nil, nil
+	}
+	err := graph.StartAll(initCtx, app.InitTimeout)
+	if err != nil && initCtx.Err() == context.DeadlineExceeded {
+		err = ErrInitTimeout
+	}
+	return graph, err
+}
+
+// stopUnits stops started units in reverse order and returns their errors.
+func (app *Apx) stopUnits(graph *di.Unit) error {
+	if graph == nil {
+		return nil
+	}
+	return graph.StopAll(app.StopTimeout)
+}
+
+// graph chains units, every unit depends on the previous one, and publishes their lifecycle events.
+// Returns the last unit of the chain.
+func (app *Apx) graph(initCtx context.Context, units []ApxUnit) *di.Unit {
+	var last *di.Unit
+	for _, u := range units {
+		u := u
+		node := di.FromLifecycle(u).Named(unitName(u)).Observe(di.Hooks{
+			Starting: func(*di.Unit) {
+				app.publish(EventUnitStarting, u, 0, nil)
+			},
+			Started: func(_ *di.Unit, d time.Duration, err error) {
+				if err == nil {
+					app.publish(EventUnitStarted, u, d, nil)
+					return
+				}
+				if initCtx.Err() == context.DeadlineExceeded {
+					err = ErrInitTimeout
+				}
+				app.publish(EventUnitFailed, u, d, err)
+			},
+			Stopping: func(*di.Unit) {
+				app.publish(EventUnitStopping, u, 0, nil)
+			},
+			Stopped: func(_ *di.Unit, d time.Duration, err error) {
+				app.publish(EventUnitStopped, u, d, err)
+				if err != nil {
+					app.Logger.Printf("unit stop error : %v", err)
+				}
+			},
+		})
+		if last != nil {
+			node.After(last)
+		}
+		last = node
+	}
+	return last
+}
+
+// watchReady checks units readiness right after the start and then every ReadyInterval until the context
+// is cancelled. The first error halts the application.
+func (app *Apx) watchReady(ctx context.Context, units []ApxUnit) {
+	ready := make([]bool, len(units))
+	check := func() bool {
+		for i, u := range units {
+			err := u.IsReady(ctx)
+			if ctx.Err() != nil {
+				return false
+			}
+			if (err == nil) != ready[i] || err != nil {
+				ready[i] = err == nil
+				app.publishEvent(Event{Kind: EventUnitReadyChanged, Unit: u, Ready: ready[i], Err: err})
+			}
+			if err != nil {
+				app.Logger.Printf("unit %s is not ready : %v", unitName(u), err)
+				app.haltWith(err)
+				return false
+			}
+		}
+		return true
+	}
+	if !check() {
+		return
+	}
+	close(app.ready)
+	if app.ReadyInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(app.ReadyInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !check() {
+				return
+			}
+		}
+	}
 }
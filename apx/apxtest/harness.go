@@ -0,0 +1,166 @@
+package apxtest
+
+import (
+	"context"
+	"io"
+	"log"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nooize/go-assist/apx"
+)
+
+/*
+ Run apx application inside unit tests
+
+ db := apxtest.NewUnit("db")
+ h := apxtest.Start(t, apxtest.NewApp("test").Add(db))
+ h.WaitReady()
+ err := h.Halt()
+ h.ExpectEvents("db starting", "db started", "db ready changed", "db stopping", "db stopped")
+*/
+
+// Timeout limits every wait of the harness, a wait which exceeds it fails the test.
+var Timeout = 5 * time.Second
+
+// NewApp returns an application with short deterministic timeouts, without OS signals handling
+// and with a discarded log.
+func NewApp(name string) *apx.Apx {
+	app := apx.New(name)
+	app.Signals = nil
+	app.Logger = log.New(io.Discard, "", 0)
+	app.InitTimeout = time.Second
+	app.StopTimeout = time.Second
+	app.TerminateTimeout = 100 * time.Millisecond
+	app.ReadyInterval = 10 * time.Millisecond
+	return app
+}
+
+// MainUntilHalt is a main function which runs until the application is halted.
+func MainUntilHalt(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// MainHang returns a main function which ignores halt and runs until the release channel is closed.
+func MainHang(release <-chan struct{}) apx.MainFunc {
+	return func(ctx context.Context) error {
+		<-release
+		return nil
+	}
+}
+
+type Harness struct {
+	t   testing.TB
+	app *apx.Apx
+
+	mu     sync.Mutex
+	events []apx.Event
+	done   chan struct{}
+	err    error
+}
+
+// Start runs the application in background. Signals handling is disabled, the application is shut down
+// when the test finishes.
+func Start(t testing.TB, app *apx.Apx) *Harness {
+	t.Helper()
+	h := &Harness{
+		t:    t,
+		app:  app,
+		done: make(chan struct{}),
+	}
+	app.Signals = nil
+	app.Listen(h.record)
+	go func() {
+		defer close(h.done)
+		err := app.Run()
+		h.mu.Lock()
+		h.err = err
+		h.mu.Unlock()
+	}()
+	t.Cleanup(func() {
+		app.Shutdown()
+		select {
+		case <-h.done:
+		case <-time.After(Timeout):
+		}
+	})
+	return h
+}
+
+func (h *Harness) record(e apx.Event) {
+	h.mu.Lock()
+	h.events = append(h.events, e)
+	h.mu.Unlock()
+}
+
+// App returns the application under test.
+func (h *Harness) App() *apx.Apx {
+	return h.app
+}
+
+// WaitReady waits until all units have reported readiness.
+func (h *Harness) WaitReady() {
+	h.t.Helper()
+	select {
+	case <-h.app.Ready():
+	case <-h.done:
+		h.t.Fatalf("application stopped before ready : %v", h.result())
+	case <-time.After(Timeout):
+		h.t.Fatalf("application is not ready after %v", Timeout)
+	}
+}
+
+// Halt halts the application and waits until Run returns, the error returned by Run is passed through.
+func (h *Harness) Halt() error {
+	h.t.Helper()
+	h.app.Halt()
+	return h.Wait()
+}
+
+// Shutdown shuts the application down and waits until Run returns, the error returned by Run is passed through.
+func (h *Harness) Shutdown() error {
+	h.t.Helper()
+	h.app.Shutdown()
+	return h.Wait()
+}
+
+// Wait waits until Run returns and passes its error through.
+func (h *Harness) Wait() error {
+	h.t.Helper()
+	select {
+	case <-h.done:
+	case <-time.After(Timeout):
+		h.t.Fatalf("application did not stop in %v", Timeout)
+	}
+	return h.result()
+}
+
+func (h *Harness) result() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
+
+// Events returns lifecycle events published so far.
+func (h *Harness) Events() []apx.Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]apx.Event(nil), h.events...)
+}
+
+// ExpectEvents fails the test when the published events differ from the expected ones. Events are
+// compared as "<unit name> <event kind>", e.g. "db started".
+func (h *Harness) ExpectEvents(expected ...string) {
+	h.t.Helper()
+	events := h.Events()
+	actual := make([]string, len(events))
+	for i, e := range events {
+		actual[i] = e.UnitName + " " + e.Kind.String()
+	}
+	if len(actual) != len(expected) || (len(actual) > 0 && !reflect.DeepEqual(actual, expected)) {
+		h.t.Fatalf("unexpected events\n  actual : %q\nexpected : %q", actual, expected)
+	}
+}
@@ -0,0 +1,146 @@
+package apxtest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nooize/go-assist/apx"
+)
+
+func TestHaltBeforeRun(t *testing.T) {
+	app := NewApp("test").Add(NewUnit("db")).Main(MainUntilHalt)
+	app.Halt()
+	h := Start(t, app)
+	if err := h.Wait(); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	h.ExpectEvents("db starting", "db started", "db stopping", "db stopped")
+}
+
+func TestHaltRightAfterStart(t *testing.T) {
+	h := Start(t, NewApp("test").Add(NewUnit("db")).Main(MainUntilHalt))
+	if err := h.Halt(); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	// the halt may come after the first readiness check
+	var kinds []string
+	for _, e := range h.Events() {
+		if e.Kind != apx.EventUnitReadyChanged {
+			kinds = append(kinds, e.UnitName+" "+e.Kind.String())
+		}
+	}
+	if fmt.Sprint(kinds) != "[db starting db started db stopping db stopped]" {
+		t.Fatalf("unexpected events : %q", kinds)
+	}
+}
+
+func TestEventOrder(t *testing.T) {
+	db, api := NewUnit("db"), NewUnit("api")
+	h := Start(t, NewApp("test").Add(db).Add(api).Main(MainUntilHalt))
+	h.WaitReady()
+	if err := h.Halt(); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	h.ExpectEvents(
+		"db starting", "db started",
+		"api starting", "api started",
+		"db ready changed", "api ready changed",
+		"api stopping", "api stopped",
+		"db stopping", "db stopped",
+	)
+}
+
+func TestFailStart(t *testing.T) {
+	fail := errors.New("no connection")
+	db, api := NewUnit("db"), NewUnit("api").FailStart(fail)
+	h := Start(t, NewApp("test").Add(db).Add(api).Main(MainUntilHalt))
+	if err := h.Wait(); err != fail {
+		t.Fatalf("expected %v, got %v", fail, err)
+	}
+	h.ExpectEvents("db starting", "db started", "api starting", "api failed", "db stopping", "db stopped")
+	if calls := fmt.Sprint(api.Calls()); calls != "[Start]" {
+		t.Fatalf("failed unit must not be stopped, calls : %s", calls)
+	}
+}
+
+func TestHangStop(t *testing.T) {
+	db := NewUnit("db").HangStop()
+	h := Start(t, NewApp("test").Add(db).Main(MainUntilHalt))
+	h.WaitReady()
+	h.App().Halt()
+
+	deadline := time.Now().Add(Timeout)
+	for fmt.Sprint(db.Calls()) != "[Start IsReady Stop]" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Stop is not called, calls : %v", db.Calls())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	events := h.Events()
+	if last := events[len(events)-1]; last.UnitName != "db" || last.Kind.String() != "stopping" {
+		t.Fatalf("expected the unit to be stopping, last event : %v", last)
+	}
+
+	db.Release()
+	if err := h.Wait(); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	h.ExpectEvents("db starting", "db started", "db ready changed", "db stopping", "db stopped")
+}
+
+func TestShutdown(t *testing.T) {
+	h := Start(t, NewApp("test").Add(NewUnit("db")).Main(MainUntilHalt))
+	h.WaitReady()
+	if err := h.Shutdown(); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	h.ExpectEvents("db starting", "db started", "db ready changed", "db stopping", "db stopped")
+}
+
+func TestShutdownHangingMain(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	h := Start(t, NewApp("test").Add(NewUnit("db")).Main(MainHang(release)))
+	h.WaitReady()
+	if err := h.Shutdown(); !errors.Is(err, apx.ErrTermTimeout) {
+		t.Fatalf("expected %v, got %v", apx.ErrTermTimeout, err)
+	}
+}
+
+func TestVerifyNoLeaks(t *testing.T) {
+	before := Snapshot()
+	h := Start(t, NewApp("test").Add(NewUnit("db")).Main(MainUntilHalt))
+	h.WaitReady()
+	if err := h.Halt(); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	VerifyNoLeaks(t, before, time.Second)
+}
+
+type fatalRecorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *fatalRecorder) Helper() {}
+
+func (r *fatalRecorder) Fatalf(string, ...interface{}) {
+	r.failed = true
+}
+
+func TestVerifyNoLeaksDetectsLeak(t *testing.T) {
+	before := Snapshot()
+	release := make(chan struct{})
+	go func() {
+		<-release
+	}()
+	defer close(release)
+
+	r := &fatalRecorder{TB: t}
+	VerifyNoLeaks(r, before, 50*time.Millisecond)
+	if !r.failed {
+		t.Fatal("leaked goroutine is not reported")
+	}
+}
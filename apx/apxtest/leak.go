@@ -0,0 +1,68 @@
+package apxtest
+
+import (
+	"bytes"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Goroutines is a snapshot of running goroutines, keyed by goroutine id.
+type Goroutines map[string]string
+
+// Snapshot returns currently running goroutines. Take it before the application is started and pass it
+// to VerifyNoLeaks after the shutdown.
+func Snapshot() Goroutines {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+	g := make(Goroutines)
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		s := string(stack)
+		// header looks like "goroutine 18 [running]:"
+		fields := strings.SplitN(s, " ", 3)
+		if len(fields) < 3 || fields[0] != "goroutine" {
+			continue
+		}
+		g[fields[1]] = s
+	}
+	return g
+}
+
+// VerifyNoLeaks fails the test when goroutines not present in the snapshot are still running after
+// the timeout. Goroutines are given the time to exit, the check is repeated until the timeout expires.
+func VerifyNoLeaks(t testing.TB, before Goroutines, timeout time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		leaked := before.leaked(Snapshot())
+		if len(leaked) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutine(s) leaked:\n\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (g Goroutines) leaked(now Goroutines) []string {
+	leaked := make([]string, 0)
+	for id, stack := range now {
+		if _, ok := g[id]; ok || strings.Contains(stack, "apxtest.Snapshot(") {
+			continue
+		}
+		leaked = append(leaked, stack)
+	}
+	sort.Strings(leaked)
+	return leaked
+}
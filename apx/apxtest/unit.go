@@ -0,0 +1,123 @@
+package apxtest
+
+import (
+	"context"
+	"sync"
+)
+
+// Unit is a scripted apx.ApxUnit. By default every call succeeds, use the Fail and Hang methods to script
+// the behaviour before the application is started.
+type Unit struct {
+	Name string
+
+	mu        sync.Mutex
+	startErr  error
+	readyErr  error
+	stopErr   error
+	hangStart bool
+	hangStop  bool
+	release   chan struct{}
+	calls     []string
+}
+
+func NewUnit(name string) *Unit {
+	return &Unit{
+		Name:    name,
+		release: make(chan struct{}),
+	}
+}
+
+// FailStart makes Start return the error.
+func (u *Unit) FailStart(err error) *Unit {
+	u.mu.Lock()
+	u.startErr = err
+	u.mu.Unlock()
+	return u
+}
+
+// FailReady makes IsReady return the error.
+func (u *Unit) FailReady(err error) *Unit {
+	u.mu.Lock()
+	u.readyErr = err
+	u.mu.Unlock()
+	return u
+}
+
+// FailStop makes Stop return the error.
+func (u *Unit) FailStop(err error) *Unit {
+	u.mu.Lock()
+	u.stopErr = err
+	u.mu.Unlock()
+	return u
+}
+
+// HangStart makes Start block until the context is done or Release is called.
+func (u *Unit) HangStart() *Unit {
+	u.mu.Lock()
+	u.hangStart = true
+	u.mu.Unlock()
+	return u
+}
+
+// HangStop makes Stop block until Release is called.
+func (u *Unit) HangStop() *Unit {
+	u.mu.Lock()
+	u.hangStop = true
+	u.mu.Unlock()
+	return u
+}
+
+// Release unblocks hanging Start and Stop calls.
+func (u *Unit) Release() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	select {
+	case <-u.release:
+	default:
+		close(u.release)
+	}
+}
+
+// Calls returns the names of the called methods in order.
+func (u *Unit) Calls() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]string(nil), u.calls...)
+}
+
+func (u *Unit) String() string {
+	return u.Name
+}
+
+func (u *Unit) Start(ctx context.Context) error {
+	u.mu.Lock()
+	u.calls = append(u.calls, "Start")
+	hang, err := u.hangStart, u.startErr
+	u.mu.Unlock()
+	if hang {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-u.release:
+		}
+	}
+	return err
+}
+
+func (u *Unit) IsReady(ctx context.Context) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.calls = append(u.calls, "IsReady")
+	return u.readyErr
+}
+
+func (u *Unit) Stop() error {
+	u.mu.Lock()
+	u.calls = append(u.calls, "Stop")
+	hang, err := u.hangStop, u.stopErr
+	u.mu.Unlock()
+	if hang {
+		<-u.release
+	}
+	return err
+}
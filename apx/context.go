@@ -0,0 +1,33 @@
+package apx
+
+import (
+	"context"
+	"log"
+)
+
+type ctxKey int
+
+const (
+	ctxKeyName ctxKey = iota
+	ctxKeyLogger
+)
+
+// NameFromContext returns the application name carried by the context passed to units and the main function.
+func NameFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(ctxKeyName).(string)
+	return name
+}
+
+// LoggerFromContext returns the application logger carried by the context, or the standard logger when
+// the context does not carry one.
+func LoggerFromContext(ctx context.Context) *log.Logger {
+	if l, ok := ctx.Value(ctxKeyLogger).(*log.Logger); ok && l != nil {
+		return l
+	}
+	return log.Default()
+}
+
+func (app *Apx) context() context.Context {
+	ctx := context.WithValue(context.Background(), ctxKeyName, app.Name)
+	return context.WithValue(ctx, ctxKeyLogger, app.Logger)
+}
@@ -0,0 +1,12 @@
+package apx
+
+import "errors"
+
+var (
+	// ErrTermTimeout is returned by Run when the main function does not return within TerminateTimeout.
+	ErrTermTimeout = errors.New("apx: main function did not terminate in time")
+	// ErrInitTimeout is returned by Run when units are not started within InitTimeout.
+	ErrInitTimeout = errors.New("apx: units did not start in time")
+	// ErrAlreadyStarted is returned by Run when the application has already been run.
+	ErrAlreadyStarted = errors.New("apx: application already started")
+)
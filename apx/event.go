@@ -0,0 +1,157 @@
+package apx
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// EventKind is a type of the unit lifecycle event.
+type EventKind int
+
+const (
+	// EventUnitStarting is published before the unit Start is called.
+	EventUnitStarting EventKind = iota
+	// EventUnitStarted is published when the unit Start returned successfully.
+	EventUnitStarted
+	// EventUnitFailed is published when the unit Start returned an error.
+	EventUnitFailed
+	// EventUnitReadyChanged is published when the unit IsReady result changes.
+	EventUnitReadyChanged
+	// EventUnitStopping is published before the unit Stop is called.
+	EventUnitStopping
+	// EventUnitStopped is published when the unit Stop returned, with or without an error.
+	EventUnitStopped
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventUnitStarting:
+		return "starting"
+	case EventUnitStarted:
+		return "started"
+	case EventUnitFailed:
+		return "failed"
+	case EventUnitReadyChanged:
+		return "ready changed"
+	case EventUnitStopping:
+		return "stopping"
+	case EventUnitStopped:
+		return "stopped"
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// Event describes a single step of the unit lifecycle.
+type Event struct {
+	Kind EventKind
+	// App is the application name.
+	App string
+	// Unit is the unit the event is about, UnitName is its printable name.
+	Unit     ApxUnit
+	UnitName string
+	Time     time.Time
+	// Duration of the Start or Stop call, set for started, failed and stopped events.
+	Duration time.Duration
+	// Ready is the new readiness state, set for ready changed events.
+	Ready bool
+	// Err is the error returned by Start, Stop or IsReady.
+	Err error
+}
+
+func (e Event) String() string {
+	s := fmt.Sprintf("unit %s %s", e.UnitName, e.Kind)
+	switch e.Kind {
+	case EventUnitStarted, EventUnitFailed, EventUnitStopped:
+		s += fmt.Sprintf(" in %v", e.Duration)
+	case EventUnitReadyChanged:
+		s += fmt.Sprintf(" to %v", e.Ready)
+	}
+	if e.Err != nil {
+		s += " : " + e.Err.Error()
+	}
+	return s
+}
+
+// Listener receives lifecycle events. Listeners are called synchronously in the order they were registered,
+// so they should not block.
+type Listener func(e Event)
+
+// Hook is called by the application before units are started or after they are stopped.
+type Hook func(ctx context.Context) error
+
+// LogEvents returns a listener which prints every event to the logger.
+func LogEvents(l *log.Logger) Listener {
+	return func(e Event) {
+		l.Print(e.String())
+	}
+}
+
+// Listen registers a lifecycle events listener.
+func (app *Apx) Listen(l Listener) *Apx {
+	if l == nil {
+		return app
+	}
+	app.unitsMu.Lock()
+	app.listeners = append(app.listeners, l)
+	app.unitsMu.Unlock()
+	return app
+}
+
+// BeforeStart registers a hook called before the first unit is started. An error returned by the hook
+// prevents the application from being launched.
+func (app *Apx) BeforeStart(h Hook) *Apx {
+	if h == nil {
+		return app
+	}
+	app.unitsMu.Lock()
+	app.beforeStart = append(app.beforeStart, h)
+	app.unitsMu.Unlock()
+	return app
+}
+
+// AfterStop registers a hook called after all units are stopped.
+func (app *Apx) AfterStop(h Hook) *Apx {
+	if h == nil {
+		return app
+	}
+	app.unitsMu.Lock()
+	app.afterStop = append(app.afterStop, h)
+	app.unitsMu.Unlock()
+	return app
+}
+
+func (app *Apx) publish(kind EventKind, u ApxUnit, d time.Duration, err error) {
+	app.publishEvent(Event{
+		Kind:     kind,
+		Unit:     u,
+		Duration: d,
+		Err:      err,
+	})
+}
+
+func (app *Apx) publishEvent(e Event) {
+	app.unitsMu.RLock()
+	listeners := app.listeners
+	app.unitsMu.RUnlock()
+	if len(listeners) == 0 {
+		return
+	}
+	e.App = app.Name
+	e.UnitName = unitName(e.Unit)
+	e.Time = time.Now()
+	for _, l := range listeners {
+		l(e)
+	}
+}
+
+func unitName(u ApxUnit) string {
+	if u == nil {
+		return ""
+	}
+	if s, ok := u.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", u)
+}
@@ -0,0 +1,223 @@
+package assist
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+/*
+ Verify and inspect certificate
+
+ cert, err := assist.LoadPemCertificate("server.pem")
+ if err = assist.CheckPrivateKey(cert); err != nil {
+    ...
+ }
+ if _, err = assist.VerifyCertificate(cert, nil, "api.example.com"); err != nil {
+    ...
+ }
+ info, _ := assist.InspectCertificate(cert)
+ log.Printf("%s expires in %v", info.Subject, info.ExpiresIn())
+*/
+
+var (
+	ErrKeyMismatch     = errors.New("tls: private key does not match certificate public key")
+	ErrCertExpired     = errors.New("tls: certificate has expired")
+	ErrCertNotYetValid = errors.New("tls: certificate is not yet valid")
+	ErrNoPrivateKey    = errors.New("tls: no private key found")
+	ErrUnsupportedKey  = errors.New("tls: unsupported key type")
+	ErrNoCertificate   = errors.New("tls: no certificate found")
+)
+
+// CertInfo is a printable summary of the x509 certificate.
+type CertInfo struct {
+	Subject      string
+	Issuer       string
+	SerialNumber string
+	NotBefore    time.Time
+	NotAfter     time.Time
+	// DNSNames, IPAddresses, EmailAddresses and URIs are subject alternative names.
+	DNSNames           []string
+	IPAddresses        []net.IP
+	EmailAddresses     []string
+	URIs               []string
+	KeyUsage           []string
+	ExtKeyUsage        []string
+	IsCA               bool
+	PublicKeyAlgorithm string
+	SignatureAlgorithm string
+}
+
+// Expired reports whether the certificate is expired at the moment.
+func (i *CertInfo) Expired() bool {
+	return time.Now().After(i.NotAfter)
+}
+
+// ExpiresIn returns the time left until the certificate expires, negative for the expired certificate.
+func (i *CertInfo) ExpiresIn() time.Duration {
+	return time.Until(i.NotAfter)
+}
+
+// LeafCertificate returns the parsed first certificate of the chain.
+func LeafCertificate(cert *tls.Certificate) (*x509.Certificate, error) {
+	if cert == nil || len(cert.Certificate) == 0 {
+		return nil, ErrNoCertificate
+	}
+	if cert.Leaf != nil {
+		return cert.Leaf, nil
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("tls: fail to parse certificate : %s", err.Error())
+	}
+	return leaf, nil
+}
+
+// InspectCertificate describes the leaf certificate of the chain.
+func InspectCertificate(cert *tls.Certificate) (*CertInfo, error) {
+	leaf, err := LeafCertificate(cert)
+	if err != nil {
+		return nil, err
+	}
+	return DescribeCertificate(leaf), nil
+}
+
+// DescribeCertificate describes the parsed x509 certificate.
+func DescribeCertificate(c *x509.Certificate) *CertInfo {
+	info := CertInfo{
+		Subject:            c.Subject.String(),
+		Issuer:             c.Issuer.String(),
+		SerialNumber:       c.SerialNumber.String(),
+		NotBefore:          c.NotBefore,
+		NotAfter:           c.NotAfter,
+		DNSNames:           c.DNSNames,
+		IPAddresses:        c.IPAddresses,
+		EmailAddresses:     c.EmailAddresses,
+		KeyUsage:           keyUsageNames(c.KeyUsage),
+		IsCA:               c.IsCA,
+		PublicKeyAlgorithm: c.PublicKeyAlgorithm.String(),
+		SignatureAlgorithm: c.SignatureAlgorithm.String(),
+	}
+	for _, u := range c.URIs {
+		info.URIs = append(info.URIs, u.String())
+	}
+	for _, u := range c.ExtKeyUsage {
+		info.ExtKeyUsage = append(info.ExtKeyUsage, extKeyUsageName(u))
+	}
+	return &info
+}
+
+// CheckPrivateKey reports ErrKeyMismatch when the private key of the certificate does not match
+// the public key of the leaf certificate, and ErrNoPrivateKey when there is no private key.
+func CheckPrivateKey(cert *tls.Certificate) error {
+	leaf, err := LeafCertificate(cert)
+	if err != nil {
+		return err
+	}
+	if cert.PrivateKey == nil {
+		return ErrNoPrivateKey
+	}
+	signer, ok := cert.PrivateKey.(crypto.Signer)
+	if !ok {
+		return ErrUnsupportedKey
+	}
+	pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok {
+		return ErrUnsupportedKey
+	}
+	if !pub.Equal(leaf.PublicKey) {
+		return ErrKeyMismatch
+	}
+	return nil
+}
+
+// VerifyCertificate verifies the chain of the certificate against the roots pool, the system pool is used
+// when roots is nil. The rest of the chain is used as intermediates. The host name is checked when dnsName
+// is not empty. Returns ErrCertExpired or ErrCertNotYetValid wrapped with the validity period for the leaf
+// out of it, and the verified chains on success.
+func VerifyCertificate(cert *tls.Certificate, roots *x509.CertPool, dnsName string) ([][]*x509.Certificate, error) {
+	leaf, err := LeafCertificate(cert)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if now.After(leaf.NotAfter) {
+		return nil, fmt.Errorf("%w : %s expired at %s", ErrCertExpired, leaf.Subject, leaf.NotAfter.Format(time.RFC3339))
+	}
+	if now.Before(leaf.NotBefore) {
+		return nil, fmt.Errorf("%w : %s is valid from %s", ErrCertNotYetValid, leaf.Subject, leaf.NotBefore.Format(time.RFC3339))
+	}
+	if roots == nil {
+		if roots, err = x509.SystemCertPool(); err != nil {
+			return nil, fmt.Errorf("tls: fail to load system roots : %s", err.Error())
+		}
+	}
+	intermediates := x509.NewCertPool()
+	for _, der := range cert.Certificate[1:] {
+		c, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("tls: fail to parse intermediate certificate : %s", err.Error())
+		}
+		intermediates.AddCert(c)
+	}
+	chains, err := leaf.Verify(x509.VerifyOptions{
+		DNSName:       dnsName,
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chains, nil
+}
+
+var keyUsages = []struct {
+	usage x509.KeyUsage
+	name  string
+}{
+	{x509.KeyUsageDigitalSignature, "digital signature"},
+	{x509.KeyUsageContentCommitment, "content commitment"},
+	{x509.KeyUsageKeyEncipherment, "key encipherment"},
+	{x509.KeyUsageDataEncipherment, "data encipherment"},
+	{x509.KeyUsageKeyAgreement, "key agreement"},
+	{x509.KeyUsageCertSign, "cert sign"},
+	{x509.KeyUsageCRLSign, "crl sign"},
+	{x509.KeyUsageEncipherOnly, "encipher only"},
+	{x509.KeyUsageDecipherOnly, "decipher only"},
+}
+
+func keyUsageNames(u x509.KeyUsage) []string {
+	var names []string
+	for _, ku := range keyUsages {
+		if u&ku.usage != 0 {
+			names = append(names, ku.name)
+		}
+	}
+	return names
+}
+
+func extKeyUsageName(u x509.ExtKeyUsage) string {
+	switch u {
+	case x509.ExtKeyUsageAny:
+		return "any"
+	case x509.ExtKeyUsageServerAuth:
+		return "server auth"
+	case x509.ExtKeyUsageClientAuth:
+		return "client auth"
+	case x509.ExtKeyUsageCodeSigning:
+		return "code signing"
+	case x509.ExtKeyUsageEmailProtection:
+		return "email protection"
+	case x509.ExtKeyUsageTimeStamping:
+		return "time stamping"
+	case x509.ExtKeyUsageOCSPSigning:
+		return "ocsp signing"
+	}
+	return fmt.Sprintf("ExtKeyUsage(%d)", int(u))
+}
@@ -0,0 +1,198 @@
+package assist
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+/*
+ Throwaway certificates for tests and internal mTLS
+
+ ca, err := assist.NewCA("Test CA", assist.KeyECDSA, 24*time.Hour)
+ server, err := ca.Issue("api", []string{"localhost", "127.0.0.1"}, assist.KeyECDSA, time.Hour)
+
+ srvCfg := &tls.Config{Certificates: []tls.Certificate{*server}}
+ cliCfg := &tls.Config{RootCAs: ca.CertPool()}
+*/
+
+// KeyType is an algorithm of the generated key pair.
+type KeyType int
+
+const (
+	// KeyECDSA is the ECDSA P-256 key.
+	KeyECDSA KeyType = iota
+	// KeyRSA is the 2048 bit RSA key.
+	KeyRSA
+	// KeyEd25519 is the Ed25519 key.
+	KeyEd25519
+)
+
+func (t KeyType) String() string {
+	switch t {
+	case KeyECDSA:
+		return "ECDSA"
+	case KeyRSA:
+		return "RSA"
+	case KeyEd25519:
+		return "Ed25519"
+	}
+	return fmt.Sprintf("KeyType(%d)", int(t))
+}
+
+// GenerateKey generates the private key of the type.
+func GenerateKey(t KeyType) (crypto.Signer, error) {
+	switch t {
+	case KeyECDSA:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KeyRSA:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case KeyEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+	return nil, fmt.Errorf("tls: unknown key type %v", t)
+}
+
+// CertAuthority issues certificates signed by its key.
+type CertAuthority struct {
+	Cert *x509.Certificate
+	Key  crypto.Signer
+}
+
+// NewCA generates the key and the self-signed CA certificate valid for ttl.
+func NewCA(commonName string, t KeyType, ttl time.Duration) (*CertAuthority, error) {
+	key, err := GenerateKey(t)
+	if err != nil {
+		return nil, err
+	}
+	tpl, err := certTemplate(commonName, ttl)
+	if err != nil {
+		return nil, err
+	}
+	tpl.IsCA = true
+	tpl.BasicConstraintsValid = true
+	tpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &CertAuthority{Cert: cert, Key: key}, nil
+}
+
+// Issue generates the key and the certificate valid for ttl, signed by the authority. Hosts are DNS names
+// or IP addresses, the certificate is usable for both server and client authentication.
+// The chain of the returned certificate includes the CA certificate.
+func (ca *CertAuthority) Issue(commonName string, hosts []string, t KeyType, ttl time.Duration) (*tls.Certificate, error) {
+	key, err := GenerateKey(t)
+	if err != nil {
+		return nil, err
+	}
+	tpl, err := certTemplate(commonName, ttl)
+	if err != nil {
+		return nil, err
+	}
+	tpl.KeyUsage = x509.KeyUsageDigitalSignature
+	if t == KeyRSA {
+		tpl.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+	tpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tpl.IPAddresses = append(tpl.IPAddresses, ip)
+		} else {
+			tpl.DNSNames = append(tpl.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, ca.Cert, key.Public(), ca.Key)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Certificate{
+		Certificate: [][]byte{der, ca.Cert.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// CertPool returns the pool with the CA certificate.
+func (ca *CertAuthority) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	return pool
+}
+
+// CertificatePem encodes the CA certificate to PEM.
+func (ca *CertAuthority) CertificatePem() []byte {
+	return EncodeCertificatesPem(ca.Cert.Raw)
+}
+
+// KeyPem encodes the CA private key to PKCS#8 PEM.
+func (ca *CertAuthority) KeyPem() ([]byte, error) {
+	return EncodePrivateKeyPem(ca.Key)
+}
+
+// EncodeCertificatesPem encodes DER certificates to PEM blocks.
+func EncodeCertificatesPem(certs ...[]byte) []byte {
+	out := make([]byte, 0)
+	for _, der := range certs {
+		out = append(out, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	return out
+}
+
+// EncodePrivateKeyPem encodes the private key to PKCS#8 PEM.
+func EncodePrivateKeyPem(key crypto.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// EncodeCertificatePem encodes the certificate chain followed by the private key to PEM, the result
+// is accepted by ParsePemCertificateWithPrivateKey.
+func EncodeCertificatePem(cert *tls.Certificate) ([]byte, error) {
+	out := EncodeCertificatesPem(cert.Certificate...)
+	if cert.PrivateKey == nil {
+		return out, nil
+	}
+	key, err := EncodePrivateKeyPem(cert.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, key...), nil
+}
+
+func certTemplate(commonName string, ttl time.Duration) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(ttl),
+	}, nil
+}
@@ -0,0 +1,203 @@
+package assist
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+)
+
+var keyTypes = []KeyType{KeyECDSA, KeyRSA, KeyEd25519}
+
+func TestIssueRoundTrip(t *testing.T) {
+	for _, caType := range keyTypes {
+		ca, err := NewCA("test CA", caType, time.Hour)
+		if err != nil {
+			t.Fatalf("%v CA : %v", caType, err)
+		}
+		for _, leafType := range keyTypes {
+			name := fmt.Sprintf("%v CA, %v leaf", caType, leafType)
+			issued, err := ca.Issue("api", []string{"api.test", "127.0.0.1"}, leafType, time.Hour)
+			if err != nil {
+				t.Fatalf("%s : fail to issue : %v", name, err)
+			}
+			data, err := EncodeCertificatePem(issued)
+			if err != nil {
+				t.Fatalf("%s : fail to encode : %v", name, err)
+			}
+			cert, err := ParsePemCertificate(data)
+			if err != nil {
+				t.Fatalf("%s : fail to parse : %v", name, err)
+			}
+			if len(cert.Certificate) != 2 {
+				t.Fatalf("%s : expected leaf and CA in the chain, got %d certificates", name, len(cert.Certificate))
+			}
+			if err = CheckPrivateKey(cert); err != nil {
+				t.Fatalf("%s : %v", name, err)
+			}
+			for _, host := range []string{"api.test", "127.0.0.1", ""} {
+				if _, err = VerifyCertificate(cert, ca.CertPool(), host); err != nil {
+					t.Fatalf("%s : fail to verify for %q : %v", name, host, err)
+				}
+			}
+			if _, err = VerifyCertificate(cert, ca.CertPool(), "other.test"); err == nil {
+				t.Fatalf("%s : certificate is verified for other host", name)
+			}
+		}
+	}
+}
+
+func TestIssuedCertificateInfo(t *testing.T) {
+	ca, err := NewCA("test CA", KeyECDSA, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caInfo := DescribeCertificate(ca.Cert)
+	if !caInfo.IsCA || caInfo.Subject != "CN=test CA" || fmt.Sprint(caInfo.KeyUsage) != "[digital signature cert sign crl sign]" {
+		t.Fatalf("unexpected CA %+v", caInfo)
+	}
+	cert, err := ca.Issue("api", []string{"api.test", "10.0.0.1"}, KeyRSA, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := InspectCertificate(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		field string
+		got   interface{}
+		want  string
+	}{
+		{"Subject", info.Subject, "CN=api"},
+		{"Issuer", info.Issuer, "CN=test CA"},
+		{"DNSNames", info.DNSNames, "[api.test]"},
+		{"IPAddresses", info.IPAddresses, "[10.0.0.1]"},
+		{"KeyUsage", info.KeyUsage, "[digital signature key encipherment]"},
+		{"ExtKeyUsage", info.ExtKeyUsage, "[server auth client auth]"},
+		{"IsCA", info.IsCA, "false"},
+		{"PublicKeyAlgorithm", info.PublicKeyAlgorithm, "RSA"},
+		{"SignatureAlgorithm", info.SignatureAlgorithm, "ECDSA-SHA256"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(tt.got); got != tt.want {
+			t.Errorf("%s : got %s, want %s", tt.field, got, tt.want)
+		}
+	}
+	if info.Expired() || info.ExpiresIn() <= 59*time.Minute || info.ExpiresIn() > time.Hour {
+		t.Errorf("unexpected validity %v - %v", info.NotBefore, info.NotAfter)
+	}
+}
+
+func TestCAKeyPem(t *testing.T) {
+	for _, kt := range keyTypes {
+		ca, err := NewCA("test CA", kt, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyPem, err := ca.KeyPem()
+		if err != nil {
+			t.Fatalf("%v : %v", kt, err)
+		}
+		cert, err := ParsePemCertificateWithPrivateKey(append(ca.CertificatePem(), keyPem...))
+		if err != nil {
+			t.Fatalf("%v : %v", kt, err)
+		}
+		if err = CheckPrivateKey(cert); err != nil {
+			t.Fatalf("%v : %v", kt, err)
+		}
+	}
+}
+
+func TestCheckPrivateKeyErrors(t *testing.T) {
+	ca, err := NewCA("test CA", KeyEd25519, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := ca.Issue("first", nil, KeyECDSA, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ca.Issue("second", nil, KeyECDSA, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		cert *tls.Certificate
+		err  error
+	}{
+		{"matching", first, nil},
+		{"mismatch", &tls.Certificate{Certificate: first.Certificate, PrivateKey: second.PrivateKey}, ErrKeyMismatch},
+		{"no key", &tls.Certificate{Certificate: first.Certificate}, ErrNoPrivateKey},
+		{"no certificate", &tls.Certificate{PrivateKey: first.PrivateKey}, ErrNoCertificate},
+		{"unsupported key", &tls.Certificate{Certificate: first.Certificate, PrivateKey: "key"}, ErrUnsupportedKey},
+	}
+	for _, tt := range tests {
+		if err := CheckPrivateKey(tt.cert); err != tt.err {
+			t.Errorf("%s : got %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestVerifyCertificateValidity(t *testing.T) {
+	ca, err := NewCA("test CA", KeyECDSA, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := ca.Issue("expired", []string{"api.test"}, KeyECDSA, -time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := InspectCertificate(expired); !info.Expired() || info.ExpiresIn() >= 0 {
+		t.Fatal("issued certificate is not expired")
+	}
+	if _, err = VerifyCertificate(expired, ca.CertPool(), "api.test"); !errors.Is(err, ErrCertExpired) {
+		t.Fatalf("expected ErrCertExpired, got %v", err)
+	}
+
+	key, err := GenerateKey(KeyECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(time.Hour),
+		NotAfter:     time.Now().Add(2 * time.Hour),
+		DNSNames:     []string{"api.test"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, ca.Cert, key.Public(), ca.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	future := &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	if _, err = VerifyCertificate(future, ca.CertPool(), "api.test"); !errors.Is(err, ErrCertNotYetValid) {
+		t.Fatalf("expected ErrCertNotYetValid, got %v", err)
+	}
+
+	other, err := NewCA("other CA", KeyECDSA, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, err := ca.Issue("valid", []string{"api.test"}, KeyECDSA, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unknown x509.UnknownAuthorityError
+	if _, err = VerifyCertificate(valid, other.CertPool(), "api.test"); !errors.As(err, &unknown) {
+		t.Fatalf("expected unknown authority, got %v", err)
+	}
+}
+
+func TestGenerateKeyUnknownType(t *testing.T) {
+	if _, err := GenerateKey(KeyType(42)); err == nil {
+		t.Fatal("unknown key type is generated")
+	}
+	if KeyType(42).String() != "KeyType(42)" || KeyEd25519.String() != "Ed25519" {
+		t.Fatal("unexpected key type names")
+	}
+}
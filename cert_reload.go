@@ -0,0 +1,142 @@
+package assist
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+ Reload certificate when files are rotated
+
+ r, err := assist.NewCertReloader("tls.crt", "tls.key")
+ r.OnReload(func(e assist.CertReloadEvent) {
+    log.Print(e)
+ })
+ stop := r.Watch(time.Minute)
+ defer stop()
+
+ srv.TLSConfig = &tls.Config{GetCertificate: r.GetCertificate}
+*/
+
+// CertReloadEvent describes the result of the certificate reload.
+type CertReloadEvent struct {
+	Time time.Time
+	// Info describes the new certificate, nil when the reload failed.
+	Info *CertInfo
+	// Err is the reason the reload failed, the previous certificate is still served.
+	Err error
+}
+
+func (e CertReloadEvent) String() string {
+	if e.Err != nil {
+		return "certificate reload failed : " + e.Err.Error()
+	}
+	return fmt.Sprintf("certificate %s reloaded, expires at %s", e.Info.Subject, e.Info.NotAfter.Format(time.RFC3339))
+}
+
+// CertReloader serves the certificate loaded from files and replaces it when the files change.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Value
+
+	mu        sync.Mutex
+	listeners []func(e CertReloadEvent)
+}
+
+// NewCertReloader loads the certificate chain and the private key, keyFile may be empty when the key
+// is in the certificate file. Fails when files can not be parsed or the key does not match the certificate.
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	cert, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.cert.Store(cert)
+	return &r, nil
+}
+
+// Certificate returns the certificate currently served.
+func (r *CertReloader) Certificate() *tls.Certificate {
+	return r.cert.Load().(*tls.Certificate)
+}
+
+// GetCertificate is the tls.Config GetCertificate callback.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// GetClientCertificate is the tls.Config GetClientCertificate callback.
+func (r *CertReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// OnReload registers the listener called after every reload, successful or not.
+func (r *CertReloader) OnReload(fn func(e CertReloadEvent)) {
+	if fn == nil {
+		return
+	}
+	r.mu.Lock()
+	r.listeners = append(r.listeners, fn)
+	r.mu.Unlock()
+}
+
+// Reload parses the files and replaces the served certificate. The previous certificate is kept when
+// files are invalid or the key does not match the certificate.
+func (r *CertReloader) Reload() error {
+	e := CertReloadEvent{Time: time.Now()}
+	cert, err := r.load()
+	if err == nil {
+		r.cert.Store(cert)
+		e.Info, _ = InspectCertificate(cert)
+	}
+	e.Err = err
+	r.mu.Lock()
+	listeners := r.listeners
+	r.mu.Unlock()
+	for _, fn := range listeners {
+		fn(e)
+	}
+	return err
+}
+
+// Watch checks the files every interval and reloads the certificate when any of them is changed.
+// A failed reload, e.g. of the certificate rotated before its key, is tried again on the next check.
+// A non-positive interval means DefaultWatchInterval. Returns the function which stops watching.
+func (r *CertReloader) Watch(interval time.Duration) (stop func()) {
+	return WatchFiles(interval, func() []string {
+		return []string{r.certFile, r.keyFile}
+	}, r.Reload)
+}
+
+func (r *CertReloader) load() (*tls.Certificate, error) {
+	data, err := os.ReadFile(r.certFile)
+	if err != nil {
+		return nil, err
+	}
+	if r.keyFile != "" {
+		key, err := os.ReadFile(r.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		data = append(append(data, '\n'), key...)
+	}
+	cert, err := ParsePemCertificateWithPrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+	if err = CheckPrivateKey(cert); err != nil {
+		return nil, err
+	}
+	if cert.Leaf, err = LeafCertificate(cert); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
@@ -0,0 +1,162 @@
+package assist
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type reloadFiles struct {
+	cert string
+	key  string
+}
+
+func newReloadFiles(t *testing.T) reloadFiles {
+	t.Helper()
+	dir := t.TempDir()
+	return reloadFiles{cert: filepath.Join(dir, "tls.crt"), key: filepath.Join(dir, "tls.key")}
+}
+
+func issueTestCert(t *testing.T, ca *CertAuthority, name string) *tls.Certificate {
+	t.Helper()
+	cert, err := ca.Issue(name, []string{name + ".test"}, KeyEd25519, time.Hour)
+	if err != nil {
+		t.Fatalf("fail to issue certificate : %v", err)
+	}
+	return cert
+}
+
+func (f reloadFiles) writeCert(t *testing.T, cert *tls.Certificate) {
+	t.Helper()
+	if err := os.WriteFile(f.cert, EncodeCertificatesPem(cert.Certificate...), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (f reloadFiles) writeKey(t *testing.T, cert *tls.Certificate) {
+	t.Helper()
+	key, err := EncodePrivateKeyPem(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(f.key, key, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// watchReloads starts watching and returns the channel of reload events.
+func watchReloads(t *testing.T, r *CertReloader) <-chan CertReloadEvent {
+	events := make(chan CertReloadEvent, 100)
+	r.OnReload(func(e CertReloadEvent) {
+		events <- e
+	})
+	t.Cleanup(r.Watch(5 * time.Millisecond))
+	return events
+}
+
+func waitReload(t *testing.T, events <-chan CertReloadEvent, ok bool) CertReloadEvent {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-events:
+			if (e.Err == nil) == ok {
+				return e
+			}
+		case <-timeout:
+			t.Fatalf("no reload with success %v", ok)
+		}
+	}
+}
+
+func servedName(r *CertReloader) string {
+	return r.Certificate().Leaf.Subject.CommonName
+}
+
+func newTestReloader(t *testing.T) (*CertAuthority, reloadFiles, *CertReloader) {
+	t.Helper()
+	ca, err := NewCA("test CA", KeyECDSA, time.Hour)
+	if err != nil {
+		t.Fatalf("fail to create CA : %v", err)
+	}
+	files := newReloadFiles(t)
+	first := issueTestCert(t, ca, "first")
+	files.writeCert(t, first)
+	files.writeKey(t, first)
+	r, err := NewCertReloader(files.cert, files.key)
+	if err != nil {
+		t.Fatalf("fail to load certificate : %v", err)
+	}
+	if servedName(r) != "first" {
+		t.Fatalf("expected first certificate, got %s", servedName(r))
+	}
+	return ca, files, r
+}
+
+func TestCertReloaderReloadsChangedFiles(t *testing.T) {
+	ca, files, r := newTestReloader(t)
+	events := watchReloads(t, r)
+
+	second := issueTestCert(t, ca, "second")
+	files.writeKey(t, second)
+	files.writeCert(t, second)
+	e := waitReload(t, events, true)
+	if e.Info == nil || e.Info.Subject != "CN=second" {
+		t.Fatalf("unexpected reload event : %v", e)
+	}
+	if servedName(r) != "second" {
+		t.Fatalf("expected second certificate, got %s", servedName(r))
+	}
+}
+
+func TestCertReloaderKeepsCertificateOnError(t *testing.T) {
+	_, files, r := newTestReloader(t)
+	events := watchReloads(t, r)
+
+	if err := os.WriteFile(files.cert, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if e := waitReload(t, events, false); e.Info != nil {
+		t.Fatalf("failed reload has certificate info : %v", e)
+	}
+	if servedName(r) != "first" {
+		t.Fatalf("expected first certificate, got %s", servedName(r))
+	}
+	if err := r.Reload(); err == nil {
+		t.Fatal("invalid files are reloaded")
+	}
+}
+
+func TestCertReloaderRetriesFailedReload(t *testing.T) {
+	ca, files, r := newTestReloader(t)
+	before, err := os.Stat(files.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := watchReloads(t, r)
+
+	// the certificate is rotated before its key, the reload fails on the key mismatch
+	second := issueTestCert(t, ca, "second")
+	files.writeCert(t, second)
+	waitReload(t, events, false)
+
+	// the key of the same size and time leaves file stamps as they were on the failed reload,
+	// so only the retry picks it up
+	files.writeKey(t, second)
+	if err := os.Chtimes(files.key, before.ModTime(), before.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	waitReload(t, events, true)
+	if servedName(r) != "second" {
+		t.Fatalf("expected second certificate, got %s", servedName(r))
+	}
+}
+
+func TestCertReloaderWatchDefaultInterval(t *testing.T) {
+	_, _, r := newTestReloader(t)
+	stop := r.Watch(0)
+	stop()
+	stop()
+}
@@ -33,29 +33,10 @@ func ParsePemCertificateWithPrivateKey(bytes []byte) (*tls.Certificate, error) {
 	return cert, nil
 }
 
+// ParsePemCertificate parses the certificate chain and the unencrypted private key,
+// see ParsePemCertificateWithPassphrase.
 func ParsePemCertificate(bytes []byte) (*tls.Certificate, error) {
-	var cert tls.Certificate
-	pool := append(make([]byte, 0), bytes...)
-	for {
-		block, rest := pem.Decode(pool)
-		if block == nil {
-			break
-		}
-		if block.Type == "CERTIFICATE" {
-			cert.Certificate = append(cert.Certificate, block.Bytes)
-		} else {
-			if pKey, err := ParseX509PrivateKey(block.Bytes); err != nil {
-				return nil, fmt.Errorf("fail to parse private key : %s", err.Error())
-			} else {
-				cert.PrivateKey = pKey
-			}
-		}
-		pool = rest
-	}
-	if len(cert.Certificate) == 0 {
-		return nil, fmt.Errorf("no certificate found")
-	}
-	return &cert, nil
+	return ParsePemCertificateWithPassphrase(bytes, nil)
 }
 
 func ParseX509PrivateKey(pem []byte) (crypto.PrivateKey, error) {
@@ -0,0 +1,96 @@
+package assist
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+
+	"golang.org/x/crypto/ssh"
+)
+
+/*
+ Serialise keys
+
+ pub, _ := assist.PublicKey(key)
+ private, _ := assist.EncodePrivateKeyPem(key)
+ public, _ := assist.EncodePublicKeyPem(pub)
+ line, _ := assist.EncodeAuthorizedKey(pub)
+ fp, _ := assist.SSHFingerprint(pub) // SHA256:...
+*/
+
+// PublicKey returns the public key of the private key, public keys are returned as is.
+func PublicKey(key interface{}) (crypto.PublicKey, error) {
+	switch key := key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	case *rsa.PrivateKey:
+		return &key.PublicKey, nil
+	case *ecdsa.PrivateKey:
+		return &key.PublicKey, nil
+	case ed25519.PrivateKey:
+		return key.Public(), nil
+	case *ed25519.PrivateKey:
+		return key.Public(), nil
+	}
+	return nil, ErrUnsupportedKey
+}
+
+// EncodePublicKeyPem encodes the public key, or the public key of the private key, to PKIX PEM.
+// The result is accepted by ParseX509PublicKey.
+func EncodePublicKeyPem(key interface{}) ([]byte, error) {
+	der, err := marshalPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// EncodeAuthorizedKey encodes the public key, or the public key of the private key, to the OpenSSH
+// authorized_keys line.
+func EncodeAuthorizedKey(key interface{}) ([]byte, error) {
+	pub, err := sshPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.MarshalAuthorizedKey(pub), nil
+}
+
+// Fingerprint returns the hex encoded SHA-256 of the PKIX encoded public key.
+func Fingerprint(key interface{}) (string, error) {
+	der, err := marshalPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SSHFingerprint returns the OpenSSH SHA-256 fingerprint of the public key, as printed by "ssh-keygen -l".
+func SSHFingerprint(key interface{}) (string, error) {
+	pub, err := sshPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	return ssh.FingerprintSHA256(pub), nil
+}
+
+func marshalPublicKey(key interface{}) ([]byte, error) {
+	pub, err := PublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return x509.MarshalPKIXPublicKey(pub)
+}
+
+func sshPublicKey(key interface{}) (ssh.PublicKey, error) {
+	pub, err := PublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.NewPublicKey(pub)
+}
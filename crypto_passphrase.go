@@ -0,0 +1,263 @@
+package assist
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/ssh"
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
+)
+
+/*
+ Load passphrase protected keys and PKCS#12 bundles
+
+ cert, err := assist.LoadPemCertificateWithPassphrase("server.pem", []byte(pass))
+ cert, err := assist.LoadPkcs12("partner.p12", pass)
+*/
+
+var ErrIncorrectPassphrase = errors.New("tls: incorrect passphrase or corrupted private key")
+
+// maxPBKDF2Iterations bounds the key derivation cost, so a crafted key can not hang the parser.
+// Tools use from 2048 (openssl) to a few hundred thousand iterations.
+const maxPBKDF2Iterations = 10_000_000
+
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA224 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 8}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC     = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+type encryptedPrivateKeyInfo struct {
+	Algo          pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KDF    pkix.AlgorithmIdentifier
+	Scheme pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+func LoadPemCertificateWithPassphrase(file string, passphrase []byte) (*tls.Certificate, error) {
+	certPEMBlock, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePemCertificateWithPassphrase(certPEMBlock, passphrase)
+}
+
+// ParsePemCertificateWithPassphrase parses CERTIFICATE blocks and the private key, which is decrypted
+// with the passphrase, see ParsePemPrivateKeyWithPassphrase.
+func ParsePemCertificateWithPassphrase(bytes []byte, passphrase []byte) (*tls.Certificate, error) {
+	var cert tls.Certificate
+	pool := append(make([]byte, 0), bytes...)
+	for {
+		block, rest := pem.Decode(pool)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			cert.Certificate = append(cert.Certificate, block.Bytes)
+		} else {
+			pKey, err := parsePemPrivateKey(block, passphrase)
+			if err != nil {
+				return nil, fmt.Errorf("fail to parse private key : %s", err.Error())
+			}
+			cert.PrivateKey = pKey
+		}
+		pool = rest
+	}
+	if len(cert.Certificate) == 0 {
+		return nil, fmt.Errorf("no certificate found")
+	}
+	return &cert, nil
+}
+
+// ParsePemPrivateKeyWithPassphrase parses the first PEM private key, which is a legacy encrypted PEM
+// with Proc-Type and DEK-Info headers, a PBES2 encrypted PKCS#8 or an OpenSSH key. Unencrypted keys
+// are accepted too. Returns ErrIncorrectPassphrase when the key can not be decrypted.
+func ParsePemPrivateKeyWithPassphrase(bytes []byte, passphrase []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(bytes)
+	if block == nil {
+		return nil, errors.New("failed to parse PEM block containing the private key")
+	}
+	return parsePemPrivateKey(block, passphrase)
+}
+
+func parsePemPrivateKey(block *pem.Block, passphrase []byte) (crypto.PrivateKey, error) {
+	switch {
+	case block.Type == "ENCRYPTED PRIVATE KEY":
+		der, err := decryptPKCS8(block.Bytes, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return ParseX509PrivateKey(der)
+	case block.Type == "OPENSSH PRIVATE KEY":
+		key, err := ssh.ParseRawPrivateKeyWithPassphrase(pem.EncodeToMemory(block), passphrase)
+		if err == x509.IncorrectPasswordError {
+			return nil, ErrIncorrectPassphrase
+		}
+		if _, ok := err.(*ssh.PassphraseMissingError); ok {
+			return ssh.ParseRawPrivateKey(pem.EncodeToMemory(block))
+		}
+		return key, err
+	case x509.IsEncryptedPEMBlock(block):
+		// legacy encryption is insecure by design, but keys are still issued this way by old tooling
+		der, err := x509.DecryptPEMBlock(block, passphrase)
+		if err != nil {
+			return nil, ErrIncorrectPassphrase
+		}
+		return ParseX509PrivateKey(der)
+	}
+	return ParseX509PrivateKey(block.Bytes)
+}
+
+// decryptPKCS8 decrypts PKCS#8 EncryptedPrivateKeyInfo with PBES2, PBKDF2 key derivation
+// and AES-CBC or 3DES-CBC encryption, as produced by "openssl pkcs8 -topk8".
+func decryptPKCS8(der []byte, passphrase []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("tls: fail to parse encrypted private key : %s", err.Error())
+	}
+	if !info.Algo.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("tls: unsupported private key encryption %s", info.Algo.Algorithm)
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algo.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("tls: fail to parse PBES2 parameters : %s", err.Error())
+	}
+	if !params.KDF.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("tls: unsupported key derivation %s", params.KDF.Algorithm)
+	}
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KDF.Parameters.FullBytes, &kdf); err != nil {
+		return nil, fmt.Errorf("tls: fail to parse PBKDF2 parameters : %s", err.Error())
+	}
+	if kdf.IterationCount < 1 || kdf.IterationCount > maxPBKDF2Iterations {
+		return nil, fmt.Errorf("tls: unsupported PBKDF2 iteration count %d", kdf.IterationCount)
+	}
+	prf, err := pbkdf2Hash(kdf.PRF.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.Scheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("tls: fail to parse cipher parameters : %s", err.Error())
+	}
+	var keyLen int
+	var newCipher func(key []byte) (cipher.Block, error)
+	switch {
+	case params.Scheme.Algorithm.Equal(oidAES128CBC):
+		keyLen, newCipher = 16, aes.NewCipher
+	case params.Scheme.Algorithm.Equal(oidAES192CBC):
+		keyLen, newCipher = 24, aes.NewCipher
+	case params.Scheme.Algorithm.Equal(oidAES256CBC):
+		keyLen, newCipher = 32, aes.NewCipher
+	case params.Scheme.Algorithm.Equal(oidDESEDE3CBC):
+		keyLen, newCipher = 24, des.NewTripleDESCipher
+	default:
+		return nil, fmt.Errorf("tls: unsupported cipher %s", params.Scheme.Algorithm)
+	}
+	block, err := newCipher(pbkdf2.Key(passphrase, kdf.Salt, kdf.IterationCount, keyLen, prf))
+	if err != nil {
+		return nil, err
+	}
+	data := info.EncryptedData
+	if len(iv) != block.BlockSize() || len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return nil, ErrIncorrectPassphrase
+	}
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+	pad := int(out[len(out)-1])
+	if pad == 0 || pad > block.BlockSize() {
+		return nil, ErrIncorrectPassphrase
+	}
+	for _, b := range out[len(out)-pad:] {
+		if int(b) != pad {
+			return nil, ErrIncorrectPassphrase
+		}
+	}
+	out = out[:len(out)-pad]
+	if _, err := x509.ParsePKCS8PrivateKey(out); err != nil {
+		return nil, ErrIncorrectPassphrase
+	}
+	return out, nil
+}
+
+func pbkdf2Hash(oid asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case len(oid) == 0, oid.Equal(oidHMACWithSHA1):
+		return sha1.New, nil
+	case oid.Equal(oidHMACWithSHA224):
+		return sha256.New224, nil
+	case oid.Equal(oidHMACWithSHA256):
+		return sha256.New, nil
+	case oid.Equal(oidHMACWithSHA384):
+		return sha512.New384, nil
+	case oid.Equal(oidHMACWithSHA512):
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("tls: unsupported PBKDF2 function %s", oid)
+}
+
+func LoadPkcs12(file string, password string) (*tls.Certificate, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePkcs12(data, password)
+}
+
+// ParsePkcs12 parses the PKCS#12 bundle with the private key and the certificate chain, the certificate
+// matching the key is moved to the head of the chain. Both PBES2 AES encrypted bundles, the default
+// of OpenSSL 3, and legacy 3DES and RC2 encrypted ones are supported.
+func ParsePkcs12(data []byte, password string) (*tls.Certificate, error) {
+	key, leaf, chain, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		if err == pkcs12.ErrIncorrectPassword {
+			return nil, ErrIncorrectPassphrase
+		}
+		return nil, fmt.Errorf("tls: fail to parse PKCS#12 : %s", err.Error())
+	}
+	cert := tls.Certificate{PrivateKey: key, Certificate: [][]byte{leaf.Raw}}
+	for _, c := range chain {
+		cert.Certificate = append(cert.Certificate, c.Raw)
+	}
+	for i := range cert.Certificate {
+		cert.Certificate[0], cert.Certificate[i] = cert.Certificate[i], cert.Certificate[0]
+		if CheckPrivateKey(&cert) == nil {
+			return &cert, nil
+		}
+		cert.Certificate[0], cert.Certificate[i] = cert.Certificate[i], cert.Certificate[0]
+	}
+	return nil, ErrKeyMismatch
+}
@@ -0,0 +1,114 @@
+package assist
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Fixtures in testdata are protected with the passphrase "secret" and made with OpenSSL 3.0 and OpenSSH:
+//
+//	openssl req -x509 -newkey ec -pkeyopt ec_paramgen_curve:P-256 -nodes -keyout p12.key -out p12.crt \
+//	    -days 36500 -subj "/CN=pkcs12.test"
+//	openssl pkcs12 -export -in p12.crt -inkey p12.key -out modern.p12 -passout pass:secret
+//	openssl pkcs12 -export -legacy -in p12.crt -inkey p12.key -out legacy.p12 -passout pass:secret
+//	openssl pkcs8 -topk8 -v2 aes-256-cbc -in ec.key -out pkcs8.key -passout pass:secret
+//	openssl rsa -in rsa.key -aes128 -traditional -out legacy.key -passout pass:secret
+//	ssh-keygen -t ed25519 -N secret -f openssh.key
+const fixturePassphrase = "secret"
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("fail to read fixture : %v", err)
+	}
+	return data
+}
+
+func TestParsePkcs12(t *testing.T) {
+	for _, name := range []string{"modern.p12", "legacy.p12"} {
+		cert, err := ParsePkcs12(readFixture(t, name), fixturePassphrase)
+		if err != nil {
+			t.Fatalf("%s : unexpected error : %v", name, err)
+		}
+		if _, ok := cert.PrivateKey.(*ecdsa.PrivateKey); !ok {
+			t.Fatalf("%s : unexpected key %T", name, cert.PrivateKey)
+		}
+		info, err := InspectCertificate(cert)
+		if err != nil || info.Subject != "CN=pkcs12.test" {
+			t.Fatalf("%s : unexpected certificate %v : %v", name, info, err)
+		}
+		if err = CheckPrivateKey(cert); err != nil {
+			t.Fatalf("%s : %v", name, err)
+		}
+		if _, err = ParsePkcs12(readFixture(t, name), "wrong"); !errors.Is(err, ErrIncorrectPassphrase) {
+			t.Fatalf("%s : expected ErrIncorrectPassphrase, got %v", name, err)
+		}
+	}
+}
+
+func TestLoadPkcs12MatchesPem(t *testing.T) {
+	p12, err := LoadPkcs12(filepath.Join("testdata", "modern.p12"), fixturePassphrase)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	pem, err := ParsePemCertificateWithPrivateKey(append(readFixture(t, "p12.crt"), readFixture(t, "p12.key")...))
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	if !p12.PrivateKey.(*ecdsa.PrivateKey).Equal(pem.PrivateKey) {
+		t.Fatal("the key of the bundle differs from the PEM one")
+	}
+}
+
+func TestParsePemPrivateKeyWithPassphrase(t *testing.T) {
+	tests := []struct {
+		name  string
+		check func(key interface{}) bool
+	}{
+		{"pkcs8.key", func(key interface{}) bool { _, ok := key.(*ecdsa.PrivateKey); return ok }},
+		{"legacy.key", func(key interface{}) bool { _, ok := key.(*rsa.PrivateKey); return ok }},
+		{"openssh.key", func(key interface{}) bool { _, ok := key.(*ed25519.PrivateKey); return ok }},
+	}
+	for _, tt := range tests {
+		data := readFixture(t, tt.name)
+		key, err := ParsePemPrivateKeyWithPassphrase(data, []byte(fixturePassphrase))
+		if err != nil {
+			t.Fatalf("%s : unexpected error : %v", tt.name, err)
+		}
+		if !tt.check(key) {
+			t.Fatalf("%s : unexpected key %T", tt.name, key)
+		}
+		if _, err = ParsePemPrivateKeyWithPassphrase(data, []byte("wrong")); !errors.Is(err, ErrIncorrectPassphrase) {
+			t.Fatalf("%s : expected ErrIncorrectPassphrase, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestParsePemCertificateWithPassphrase(t *testing.T) {
+	ca, err := NewCA("test CA", KeyECDSA, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParsePemPrivateKeyWithPassphrase(readFixture(t, "pkcs8.key"), []byte(fixturePassphrase))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := append(ca.CertificatePem(), readFixture(t, "pkcs8.key")...)
+	cert, err := ParsePemCertificateWithPassphrase(data, []byte(fixturePassphrase))
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	if !cert.PrivateKey.(*ecdsa.PrivateKey).Equal(key) {
+		t.Fatal("unexpected private key")
+	}
+	if _, err = ParsePemCertificate(data); err == nil {
+		t.Fatal("encrypted key is parsed without the passphrase")
+	}
+}
@@ -0,0 +1,387 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/nooize/go-assist/lifecycle"
+)
+
+/*
+ Typed Dependency Injection container
+
+ c := di.NewContainer()
+ di.Supply(c, cfg)
+ di.Provide1(c, openDB)      // func(cfg *Config) (*sql.DB, error)
+ di.Provide2(c, newRepo)     // func(cfg *Config, db *sql.DB) (*Repo, error)
+ repo, err := di.Resolve[*Repo](c)
+
+ Named providers, value groups and optional dependencies
+
+ di.Provide1(c, openReplica, di.Named("replica"))
+ di.Provide2(c, newReport, di.Params(di.Param{}, di.Param{Name: "replica"}))
+ di.Provide(c, newUsersRoutes, di.Group("routes"))  // func() (Routes, error)
+ di.Provide1(c, newRouter, di.Params(di.Param{Group: "routes"}))  // func(routes []Routes) (*Router, error)
+*/
+
+var (
+	// ErrMissingProvider is returned when no provider is registered for the required type.
+	ErrMissingProvider = errors.New("missing provider")
+	// ErrCycle is returned when providers depend on each other.
+	ErrCycle = errors.New("dependency cycle")
+)
+
+// Scope defines how often the constructor of a provider is called.
+type Scope int
+
+const (
+	// Singleton providers are called once, the value is shared by all dependants.
+	Singleton Scope = iota
+	// Transient providers are called on every resolve.
+	Transient
+)
+
+// Starter is implemented by values which must be started before use. Resolved values implementing Starter,
+// Stopper or lifecycle.Unit are registered in the container start order, see Container.Units.
+type Starter interface {
+	Start() error
+}
+
+// Stopper is implemented by values which must be stopped on shutdown.
+type Stopper interface {
+	Stop() error
+}
+
+// ResolveError describes a failed resolve, Path lists types from the resolved one to the failed one.
+type ResolveError struct {
+	Path []string
+	Err  error
+}
+
+func (e *ResolveError) Error() string {
+	return "di: resolve " + strings.Join(e.Path, " -> ") + " : " + e.Err.Error()
+}
+
+func (e *ResolveError) Unwrap() error {
+	return e.Err
+}
+
+// Option configures a provider.
+type Option func(p *provider)
+
+// WithScope sets the provider scope, Singleton by default.
+func WithScope(s Scope) Option {
+	return func(p *provider) {
+		p.scope = s
+	}
+}
+
+// Named registers the provider under the name, so several providers of the same type may coexist.
+// Named values are injected with Params or resolved with ResolveNamed.
+func Named(name string) Option {
+	return func(p *provider) {
+		p.key.name = name
+	}
+}
+
+// Group adds the provider to the value group. Values of the group with the type T are injected as []T
+// with Params or resolved with ResolveGroup.
+func Group(name string) Option {
+	return func(p *provider) {
+		p.key.group = name
+	}
+}
+
+// Param describes how the constructor parameter is resolved.
+type Param struct {
+	// Name of the provider, see Named.
+	Name string
+	// Group of the values, the parameter type must be a slice of the group values type, see Group.
+	// The group without members is injected as an empty slice.
+	Group string
+	// Optional parameters resolve to the zero value when no provider is registered.
+	Optional bool
+}
+
+// Params describes constructor parameters by position, parameters not listed are resolved by type.
+func Params(params ...Param) Option {
+	return func(p *provider) {
+		p.params = params
+	}
+}
+
+type typeKey[T any] struct{}
+
+type providerKey struct {
+	t     interface{}
+	name  string
+	group string
+}
+
+func (k providerKey) describe(typeName string) string {
+	switch {
+	case k.group != "":
+		return typeName + " group " + k.group
+	case k.name != "":
+		return typeName + " named " + k.name
+	}
+	return typeName
+}
+
+type provider struct {
+	key    providerKey
+	name   string
+	scope  Scope
+	params []Param
+	build  func(r *resolver) (interface{}, error)
+
+	done  bool
+	value interface{}
+	units []*Unit
+}
+
+type Container struct {
+	mu        sync.Mutex
+	providers map[providerKey]*provider
+	groups    map[providerKey][]*provider
+	units     []*Unit
+}
+
+func NewContainer() *Container {
+	return &Container{
+		providers: make(map[providerKey]*provider),
+		groups:    make(map[providerKey][]*provider),
+	}
+}
+
+// Units returns units of resolved values implementing Starter, Stopper or lifecycle.Unit, in the order
+// they were created.
+// Every unit is linked with After to the units of its dependencies.
+func (c *Container) Units() []*Unit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Unit(nil), c.units...)
+}
+
+func provide[T any](c *Container, build func(r *resolver) (interface{}, error), opts []Option) {
+	p := &provider{
+		key:   providerKey{t: typeKey[T]{}},
+		scope: Singleton,
+		build: build,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	p.name = p.key.describe(typeName[T]())
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p.key.group == "" {
+		c.providers[p.key] = p
+		return
+	}
+	members := c.groups[p.key]
+	c.groups[p.key] = append(members, p)
+	if len(members) > 0 {
+		return
+	}
+	// the first member of the group registers the collector of []T
+	key := p.key
+	c.providers[providerKey{t: typeKey[[]T]{}, group: key.group}] = &provider{
+		name:  key.describe("[]" + typeName[T]()),
+		scope: Transient,
+		build: func(r *resolver) (interface{}, error) {
+			members := r.c.groups[key]
+			list := make([]T, 0, len(members))
+			for _, m := range members {
+				v, err := r.build(m)
+				if err != nil {
+					return nil, err
+				}
+				t, _ := v.(T)
+				list = append(list, t)
+			}
+			return list, nil
+		},
+	}
+}
+
+// Supply registers a ready value.
+func Supply[T any](c *Container, v T, opts ...Option) {
+	Provide(c, func() (T, error) { return v, nil }, opts...)
+}
+
+// Resolve returns the value of type T built by its provider and providers of its dependencies.
+// Constructors are called under the container lock, so they must not call Resolve on the same container.
+func Resolve[T any](c *Container) (T, error) {
+	return resolveKey[T](c, providerKey{t: typeKey[T]{}})
+}
+
+// ResolveNamed returns the value of type T built by the provider registered with the name.
+func ResolveNamed[T any](c *Container, name string) (T, error) {
+	return resolveKey[T](c, providerKey{t: typeKey[T]{}, name: name})
+}
+
+// ResolveGroup returns values of all providers of the group with the type T, in registration order.
+// The group without providers resolves to an empty slice.
+func ResolveGroup[T any](c *Container, group string) ([]T, error) {
+	return resolveKey[[]T](c, providerKey{t: typeKey[[]T]{}, group: group})
+}
+
+// MustResolve is like Resolve but panics on error.
+func MustResolve[T any](c *Container) T {
+	v, err := Resolve[T](c)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func resolveKey[T any](c *Container, key providerKey) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return resolve[T](&resolver{c: c}, key, false)
+}
+
+type resolver struct {
+	c      *Container
+	stack  []*provider
+	frames [][]*Unit
+}
+
+func resolve[T any](r *resolver, key providerKey, optional bool) (T, error) {
+	var zero T
+	p, ok := r.c.providers[key]
+	if !ok {
+		// the group without members resolves to an empty slice
+		if t := reflect.TypeOf(&zero).Elem(); key.group != "" && t.Kind() == reflect.Slice {
+			return reflect.MakeSlice(t, 0, 0).Interface().(T), nil
+		}
+		if optional {
+			return zero, nil
+		}
+		return zero, r.fail(key.describe(typeName[T]()), ErrMissingProvider)
+	}
+	v, err := r.build(p)
+	if err != nil || v == nil {
+		return zero, err
+	}
+	return v.(T), nil
+}
+
+// resolveParam resolves the parameter of the constructor being built by its position.
+func resolveParam[T any](r *resolver, i int) (T, error) {
+	key := providerKey{t: typeKey[T]{}}
+	var param Param
+	if params := r.stack[len(r.stack)-1].params; i < len(params) {
+		param = params[i]
+	}
+	key.name, key.group = param.Name, param.Group
+	return resolve[T](r, key, param.Optional)
+}
+
+func (r *resolver) build(p *provider) (interface{}, error) {
+	for _, s := range r.stack {
+		if s == p {
+			return nil, r.fail(p.name, ErrCycle)
+		}
+	}
+	if p.scope == Singleton && p.done {
+		r.collect(p.units)
+		return p.value, nil
+	}
+
+	r.stack = append(r.stack, p)
+	r.frames = append(r.frames, nil)
+	v, err := p.build(r)
+	units := r.frames[len(r.frames)-1]
+	r.stack = r.stack[:len(r.stack)-1]
+	r.frames = r.frames[:len(r.frames)-1]
+	if err != nil {
+		if _, ok := err.(*ResolveError); !ok {
+			err = &ResolveError{Path: append(r.path(), p.name), Err: err}
+		}
+		return nil, err
+	}
+
+	if u := lifecycleUnit(v); u != nil {
+		u.Named(p.name)
+		for _, d := range units {
+			u.After(d)
+		}
+		units = []*Unit{u}
+		r.c.units = append(r.c.units, u)
+	}
+	if p.scope == Singleton {
+		p.done, p.value, p.units = true, v, units
+	}
+	r.collect(units)
+	return v, nil
+}
+
+// collect adds units to the dependencies of the provider being built.
+func (r *resolver) collect(units []*Unit) {
+	if len(r.frames) == 0 {
+		return
+	}
+	i := len(r.frames) - 1
+	for _, u := range units {
+		if !containsUnit(r.frames[i], u) {
+			r.frames[i] = append(r.frames[i], u)
+		}
+	}
+}
+
+func (r *resolver) path() []string {
+	path := make([]string, len(r.stack))
+	for i, p := range r.stack {
+		path[i] = p.name
+	}
+	return path
+}
+
+func (r *resolver) fail(name string, err error) error {
+	return &ResolveError{Path: append(r.path(), name), Err: err}
+}
+
+func lifecycleUnit(v interface{}) *Unit {
+	if l, ok := v.(lifecycle.Unit); ok {
+		return FromLifecycle(l)
+	}
+	starter, isStarter := v.(Starter)
+	stopper, isStopper := v.(Stopper)
+	if !isStarter && !isStopper {
+		return nil
+	}
+	return NewUnit(
+		func(state chan *UnitState) {
+			var err error
+			if isStarter {
+				err = starter.Start()
+			}
+			state <- &UnitState{Error: err}
+		},
+		func(state chan *UnitState) {
+			var err error
+			if isStopper {
+				err = stopper.Stop()
+			}
+			state <- &UnitState{Error: err}
+		},
+	)
+}
+
+func containsUnit(list []*Unit, u *Unit) bool {
+	for _, p := range list {
+		if p == u {
+			return true
+		}
+	}
+	return false
+}
+
+func typeName[T any]() string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", new(T)), "*")
+}
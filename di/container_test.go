@@ -0,0 +1,286 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testConfig struct {
+	DSN string
+}
+
+type testDB struct {
+	dsn     string
+	started bool
+	stopped bool
+}
+
+func (d *testDB) Start() error {
+	d.started = true
+	return nil
+}
+
+func (d *testDB) Stop() error {
+	d.stopped = true
+	return nil
+}
+
+type testRepo struct {
+	db *testDB
+}
+
+type testServer struct {
+	repo *testRepo
+	cfg  *testConfig
+}
+
+func (s *testServer) Start() error {
+	return nil
+}
+
+func newTestContainer() *Container {
+	c := NewContainer()
+	Supply(c, &testConfig{DSN: "postgres://db"})
+	Provide1(c, func(cfg *testConfig) (*testDB, error) {
+		return &testDB{dsn: cfg.DSN}, nil
+	})
+	Provide1(c, func(db *testDB) (*testRepo, error) {
+		return &testRepo{db: db}, nil
+	})
+	Provide2(c, func(repo *testRepo, cfg *testConfig) (*testServer, error) {
+		return &testServer{repo: repo, cfg: cfg}, nil
+	})
+	return c
+}
+
+func TestResolve(t *testing.T) {
+	c := newTestContainer()
+	srv, err := Resolve[*testServer](c)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	if srv.repo.db.dsn != "postgres://db" || srv.cfg.DSN != "postgres://db" {
+		t.Fatalf("unexpected server %+v", srv)
+	}
+	if db := MustResolve[*testDB](c); db != srv.repo.db {
+		t.Fatal("singleton is built twice")
+	}
+}
+
+func TestProvideArity(t *testing.T) {
+	c := NewContainer()
+	Supply(c, 1)
+	Supply(c, "a")
+	Supply(c, true)
+	Supply(c, 1.5)
+	Provide3(c, func(i int, s string, b bool) ([]interface{}, error) {
+		return []interface{}{i, s, b}, nil
+	})
+	Provide4(c, func(i int, s string, b bool, f float64) (map[string]interface{}, error) {
+		return map[string]interface{}{"i": i, "s": s, "b": b, "f": f}, nil
+	})
+	three, err := Resolve[[]interface{}](c)
+	if err != nil || !reflect.DeepEqual(three, []interface{}{1, "a", true}) {
+		t.Fatalf("Provide3 : got %v, %v", three, err)
+	}
+	four, err := Resolve[map[string]interface{}](c)
+	if err != nil || !reflect.DeepEqual(four, map[string]interface{}{"i": 1, "s": "a", "b": true, "f": 1.5}) {
+		t.Fatalf("Provide4 : got %v, %v", four, err)
+	}
+}
+
+func TestScope(t *testing.T) {
+	tests := []struct {
+		scope Scope
+		calls int
+	}{
+		{Singleton, 1},
+		{Transient, 3},
+	}
+	for _, tt := range tests {
+		c := NewContainer()
+		calls := 0
+		Provide(c, func() (*testConfig, error) {
+			calls++
+			return &testConfig{}, nil
+		}, WithScope(tt.scope))
+		for i := 0; i < 3; i++ {
+			if _, err := Resolve[*testConfig](c); err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+		}
+		if calls != tt.calls {
+			t.Errorf("scope %d : got %d calls, want %d", tt.scope, calls, tt.calls)
+		}
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	failure := errors.New("connection refused")
+	tests := []struct {
+		name    string
+		provide func(c *Container)
+		err     error
+		path    []string
+	}{
+		{
+			name:    "missing",
+			provide: func(c *Container) {},
+			err:     ErrMissingProvider,
+			path:    []string{"*di.testServer", "*di.testRepo", "*di.testDB", "*di.testConfig"},
+		},
+		{
+			name: "failed constructor",
+			provide: func(c *Container) {
+				Provide(c, func() (*testConfig, error) { return nil, failure })
+			},
+			err:  failure,
+			path: []string{"*di.testServer", "*di.testRepo", "*di.testDB", "*di.testConfig"},
+		},
+		{
+			name: "cycle",
+			provide: func(c *Container) {
+				Provide1(c, func(*testServer) (*testConfig, error) { return &testConfig{}, nil })
+			},
+			err:  ErrCycle,
+			path: []string{"*di.testServer", "*di.testRepo", "*di.testDB", "*di.testConfig", "*di.testServer"},
+		},
+	}
+	for _, tt := range tests {
+		c := NewContainer()
+		Provide1(c, func(cfg *testConfig) (*testDB, error) { return &testDB{}, nil })
+		Provide1(c, func(db *testDB) (*testRepo, error) { return &testRepo{db: db}, nil })
+		Provide1(c, func(repo *testRepo) (*testServer, error) { return &testServer{repo: repo}, nil })
+		tt.provide(c)
+		_, err := Resolve[*testServer](c)
+		var resolveErr *ResolveError
+		if !errors.Is(err, tt.err) || !errors.As(err, &resolveErr) {
+			t.Errorf("%s : got %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if !reflect.DeepEqual(resolveErr.Path, tt.path) {
+			t.Errorf("%s : got path %v, want %v", tt.name, resolveErr.Path, tt.path)
+		}
+	}
+}
+
+func TestMustResolvePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustResolve does not panic on missing provider")
+		}
+	}()
+	MustResolve[*testServer](NewContainer())
+}
+
+func TestContainerUnits(t *testing.T) {
+	c := newTestContainer()
+	srv := MustResolve[*testServer](c)
+	units := c.Units()
+	if len(units) != 2 {
+		t.Fatalf("expected units of the db and the server, got %d", len(units))
+	}
+	db, server := units[0], units[1]
+	if db.name != "*di.testDB" || server.name != "*di.testServer" {
+		t.Fatalf("unexpected units %s, %s", db.name, server.name)
+	}
+	if !reflect.DeepEqual(server.after, []*Unit{db}) {
+		t.Fatal("server unit does not go after the db unit it depends on through the repo")
+	}
+	if err := server.StartAll(context.Background(), DefaultStartTimeout); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	if !srv.repo.db.started {
+		t.Fatal("db is not started")
+	}
+	if err := server.StopAll(DefaultStopTimeout); err != nil || !srv.repo.db.stopped {
+		t.Fatalf("db is not stopped : %v", err)
+	}
+}
+
+func TestNamed(t *testing.T) {
+	c := NewContainer()
+	Supply(c, &testConfig{DSN: "primary"})
+	Supply(c, &testConfig{DSN: "replica"}, Named("replica"))
+	Provide2(c, func(primary, replica *testConfig) (string, error) {
+		return primary.DSN + "," + replica.DSN, nil
+	}, Params(Param{}, Param{Name: "replica"}))
+
+	tests := []struct {
+		name string
+		want string
+		err  error
+	}{
+		{"", "primary", nil},
+		{"replica", "replica", nil},
+		{"archive", "", ErrMissingProvider},
+	}
+	for _, tt := range tests {
+		cfg, err := ResolveNamed[*testConfig](c, tt.name)
+		if !errors.Is(err, tt.err) || (err == nil && cfg.DSN != tt.want) {
+			t.Errorf("%q : got %v, %v, want %s, %v", tt.name, cfg, err, tt.want, tt.err)
+		}
+	}
+	if s, err := Resolve[string](c); err != nil || s != "primary,replica" {
+		t.Fatalf("named parameter : got %q, %v", s, err)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	c := NewContainer()
+	for _, name := range []string{"users", "orders", "health"} {
+		name := name
+		Provide(c, func() (string, error) { return name, nil }, Group("routes"))
+	}
+	Provide1(c, func(routes []string) (int, error) {
+		return len(routes), nil
+	}, Params(Param{Group: "routes"}))
+	Provide1(c, func(jobs []string) (bool, error) {
+		return jobs != nil && len(jobs) == 0, nil
+	}, Params(Param{Group: "jobs"}))
+
+	routes, err := ResolveGroup[string](c, "routes")
+	if err != nil || !reflect.DeepEqual(routes, []string{"users", "orders", "health"}) {
+		t.Fatalf("routes : got %v, %v", routes, err)
+	}
+	if n, err := Resolve[int](c); err != nil || n != 3 {
+		t.Fatalf("group parameter : got %d, %v", n, err)
+	}
+	jobs, err := ResolveGroup[string](c, "jobs")
+	if err != nil || jobs == nil || len(jobs) != 0 {
+		t.Fatalf("empty group : got %#v, %v", jobs, err)
+	}
+	if empty, err := Resolve[bool](c); err != nil || !empty {
+		t.Fatalf("empty group parameter : got %v, %v", empty, err)
+	}
+	if _, err := Resolve[string](c); !errors.Is(err, ErrMissingProvider) {
+		t.Fatalf("group member is resolved by type : %v", err)
+	}
+}
+
+func TestOptional(t *testing.T) {
+	tests := []struct {
+		name  string
+		param Param
+		err   error
+	}{
+		{"optional", Param{Optional: true}, nil},
+		{"optional named", Param{Name: "replica", Optional: true}, nil},
+		{"required", Param{}, ErrMissingProvider},
+	}
+	for _, tt := range tests {
+		c := NewContainer()
+		Provide1(c, func(cfg *testConfig) (*testDB, error) {
+			if cfg == nil {
+				return &testDB{dsn: "default"}, nil
+			}
+			return &testDB{dsn: cfg.DSN}, nil
+		}, Params(tt.param))
+		db, err := Resolve[*testDB](c)
+		if !errors.Is(err, tt.err) || (err == nil && db.dsn != "default") {
+			t.Errorf("%s : got %v, %v, want %v", tt.name, db, err, tt.err)
+		}
+	}
+}
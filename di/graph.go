@@ -0,0 +1,117 @@
+package di
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CycleError describes a dependency cycle, Path starts and ends with the same unit.
+type CycleError struct {
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return "di: " + ErrCycle.Error() + " : " + strings.Join(e.Path, " -> ")
+}
+
+func (e *CycleError) Unwrap() error {
+	return ErrCycle
+}
+
+// Named sets the unit name used in errors and graph exports.
+func (m *Unit) Named(name string) *Unit {
+	m.name = name
+	return m
+}
+
+// Validate checks the graph of units the unit depends on and returns a CycleError
+// when units depend on each other.
+func (m *Unit) Validate() error {
+	names := m.names()
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	marks := make(map[*Unit]int)
+	stack := make([]*Unit, 0)
+	var visit func(u *Unit) error
+	visit = func(u *Unit) error {
+		switch marks[u] {
+		case visited:
+			return nil
+		case visiting:
+			path := make([]string, 0)
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i] == u {
+					for _, p := range stack[i:] {
+						path = append(path, names[p])
+					}
+					break
+				}
+			}
+			return &CycleError{Path: append(path, names[u])}
+		}
+		marks[u] = visiting
+		stack = append(stack, u)
+		for _, d := range u.after {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		marks[u] = visited
+		return nil
+	}
+	return visit(m)
+}
+
+// DOT returns the graph of units in Graphviz DOT format. Edges point from a unit to its dependants,
+// so the graph reads in boot order.
+func (m *Unit) DOT() string {
+	names := m.names()
+	var b strings.Builder
+	b.WriteString("digraph units {\n")
+	b.WriteString("\trankdir=LR;\n")
+	for _, u := range m.startOrder() {
+		fmt.Fprintf(&b, "\t%q;\n", names[u])
+	}
+	for _, u := range m.startOrder() {
+		for _, d := range u.after {
+			fmt.Fprintf(&b, "\t%q -> %q;\n", names[d], names[u])
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid returns the graph of units as a Mermaid flowchart, edges point in boot order like in DOT.
+func (m *Unit) Mermaid() string {
+	names := m.names()
+	ids := make(map[*Unit]string)
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, u := range m.startOrder() {
+		ids[u] = fmt.Sprintf("u%d", i)
+		fmt.Fprintf(&b, "    %s[%q]\n", ids[u], names[u])
+	}
+	for _, u := range m.startOrder() {
+		for _, d := range u.after {
+			fmt.Fprintf(&b, "    %s --> %s\n", ids[d], ids[u])
+		}
+	}
+	return b.String()
+}
+
+// names returns printable names of the unit and all units it depends on, unnamed units are numbered
+// in start order.
+func (m *Unit) names() map[*Unit]string {
+	names := make(map[*Unit]string)
+	for i, u := range m.startOrder() {
+		if u.name != "" {
+			names[u] = u.name
+		} else {
+			names[u] = fmt.Sprintf("unit%d", i+1)
+		}
+	}
+	return names
+}
@@ -0,0 +1,123 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func noopUnit(name string) *Unit {
+	done := func(state chan *UnitState) {
+		state <- &UnitState{}
+	}
+	return NewUnit(done, done).Named(name)
+}
+
+// chain returns units where every unit goes after the previous one.
+func chain(names ...string) []*Unit {
+	units := make([]*Unit, len(names))
+	for i, name := range names {
+		units[i] = noopUnit(name)
+		if i > 0 {
+			units[i].After(units[i-1])
+		}
+	}
+	return units
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func() *Unit
+		path  []string
+	}{
+		{"chain", func() *Unit { return chain("a", "b", "c")[2] }, nil},
+		{"diamond", func() *Unit {
+			u := chain("db", "repo", "web")
+			u[2].After(noopUnit("cache").After(u[0]))
+			return u[2]
+		}, nil},
+		{"self", func() *Unit {
+			u := noopUnit("a")
+			return u.After(u)
+		}, []string{"a", "a"}},
+		{"cycle", func() *Unit {
+			u := chain("a", "b", "c")
+			u[0].After(u[2])
+			return u[2]
+		}, []string{"c", "b", "a", "c"}},
+		{"cycle below", func() *Unit {
+			u := chain("a", "b", "c", "web")
+			u[1].After(u[2])
+			return u[3]
+		}, []string{"c", "b", "c"}},
+	}
+	for _, tt := range tests {
+		err := tt.build().Validate()
+		if tt.path == nil {
+			if err != nil {
+				t.Errorf("%s : unexpected error : %v", tt.name, err)
+			}
+			continue
+		}
+		var cycle *CycleError
+		if !errors.As(err, &cycle) || !errors.Is(err, ErrCycle) {
+			t.Errorf("%s : expected CycleError, got %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(cycle.Path, tt.path) {
+			t.Errorf("%s : got path %v, want %v", tt.name, cycle.Path, tt.path)
+		}
+	}
+}
+
+func TestStartAllRejectsCycle(t *testing.T) {
+	u := chain("a", "b")
+	u[0].After(u[1])
+	if err := u[1].StartAll(context.Background(), DefaultStartTimeout); !errors.Is(err, ErrCycle) {
+		t.Fatalf("expected ErrCycle, got %v", err)
+	}
+	if u[0].running || u[1].running {
+		t.Fatal("units of the cycle are started")
+	}
+	svc := NewService("svc")
+	svc.After(u[1])
+	if code, err := svc.Start(); code != 1 || !errors.Is(err, ErrCycle) {
+		t.Fatalf("service : got %d, %v", code, err)
+	}
+}
+
+func TestGraphExport(t *testing.T) {
+	u := chain("db", "repo", "web")
+	u[2].After(u[0])
+	u[2].After(noopUnit(""))
+
+	dot := "digraph units {\n" +
+		"\trankdir=LR;\n" +
+		"\t\"db\";\n" +
+		"\t\"repo\";\n" +
+		"\t\"unit3\";\n" +
+		"\t\"web\";\n" +
+		"\t\"db\" -> \"repo\";\n" +
+		"\t\"repo\" -> \"web\";\n" +
+		"\t\"db\" -> \"web\";\n" +
+		"\t\"unit3\" -> \"web\";\n" +
+		"}\n"
+	if got := u[2].DOT(); got != dot {
+		t.Errorf("DOT : got\n%s\nwant\n%s", got, dot)
+	}
+
+	mermaid := "flowchart LR\n" +
+		"    u0[\"db\"]\n" +
+		"    u1[\"repo\"]\n" +
+		"    u2[\"unit3\"]\n" +
+		"    u3[\"web\"]\n" +
+		"    u0 --> u1\n" +
+		"    u1 --> u3\n" +
+		"    u0 --> u3\n" +
+		"    u2 --> u3\n"
+	if got := u[2].Mermaid(); got != mermaid {
+		t.Errorf("Mermaid : got\n%s\nwant\n%s", got, mermaid)
+	}
+}
@@ -0,0 +1,107 @@
+package di
+
+import (
+	"context"
+	"time"
+
+	"github.com/nooize/go-assist/lifecycle"
+)
+
+// Lifecycle returns the unit with all units it depends on as a single lifecycle, e.g. to be added to
+// apx application. Start validates and starts the graph, each unit within the context deadline or
+// DefaultStartTimeout. Stop stops the unit and then its dependencies in reverse dependency order.
+func (m *Unit) Lifecycle() lifecycle.Unit {
+	return unitLifecycle{m}
+}
+
+type unitLifecycle struct {
+	u *Unit
+}
+
+func (l unitLifecycle) Start(ctx context.Context) error {
+	timeout := DefaultStartTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	return l.u.StartAll(ctx, timeout)
+}
+
+func (l unitLifecycle) IsReady(ctx context.Context) error {
+	for _, u := range l.u.startOrder() {
+		if err := u.isReady(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l unitLifecycle) Stop() error {
+	return l.u.StopAll(DefaultStopTimeout)
+}
+
+// StartAll validates the graph and starts the units the unit depends on and then the unit itself,
+// independent units concurrently, each within the timeout. This is the runner apx starts its units with.
+func (m *Unit) StartAll(ctx context.Context, timeout time.Duration) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	return m.run(ctx, timeout)
+}
+
+// StopAll stops the running unit and then all running units it depends on in reverse dependency order,
+// each within the timeout. Errors of all units are collected.
+func (m *Unit) StopAll(timeout time.Duration) error {
+	return m.stopAll(timeout)
+}
+
+// Hooks are called by the runner around the start and the stop of the unit, e.g. to publish lifecycle
+// events. Any of them may be nil. Started and Stopped receive the duration and the error of the call.
+type Hooks struct {
+	Starting func(u *Unit)
+	Started  func(u *Unit, d time.Duration, err error)
+	Stopping func(u *Unit)
+	Stopped  func(u *Unit, d time.Duration, err error)
+}
+
+// Observe sets hooks of the unit.
+func (m *Unit) Observe(h Hooks) *Unit {
+	m.lock.Lock()
+	m.hooks = h
+	m.lock.Unlock()
+	return m
+}
+
+func (h Hooks) starting(u *Unit) {
+	if h.Starting != nil {
+		h.Starting(u)
+	}
+}
+
+func (h Hooks) started(u *Unit, d time.Duration, err error) {
+	if h.Started != nil {
+		h.Started(u, d, err)
+	}
+}
+
+func (h Hooks) stopping(u *Unit) {
+	if h.Stopping != nil {
+		h.Stopping(u)
+	}
+}
+
+func (h Hooks) stopped(u *Unit, d time.Duration, err error) {
+	if h.Stopped != nil {
+		h.Stopped(u, d, err)
+	}
+}
+
+// isReady reports ErrNotRunning for the unit which is not running, otherwise asks the lifecycle.
+func (m *Unit) isReady(ctx context.Context) error {
+	m.lock.Lock()
+	running := m.running
+	m.lock.Unlock()
+	if !running {
+		return ErrNotRunning
+	}
+	return m.instance.IsReady(ctx)
+}
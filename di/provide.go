@@ -0,0 +1,76 @@
+package di
+
+// Provide registers a constructor without dependencies for the type T.
+func Provide[T any](c *Container, fn func() (T, error), opts ...Option) {
+	provide[T](c, func(r *resolver) (interface{}, error) {
+		return fn()
+	}, opts)
+}
+
+// Provide1 registers a constructor of the type T with one dependency.
+func Provide1[T, A any](c *Container, fn func(A) (T, error), opts ...Option) {
+	provide[T](c, func(r *resolver) (interface{}, error) {
+		a, err := resolveParam[A](r, 0)
+		if err != nil {
+			return nil, err
+		}
+		return fn(a)
+	}, opts)
+}
+
+// Provide2 registers a constructor of the type T with two dependencies.
+func Provide2[T, A, B any](c *Container, fn func(A, B) (T, error), opts ...Option) {
+	provide[T](c, func(r *resolver) (interface{}, error) {
+		a, err := resolveParam[A](r, 0)
+		if err != nil {
+			return nil, err
+		}
+		b, err := resolveParam[B](r, 1)
+		if err != nil {
+			return nil, err
+		}
+		return fn(a, b)
+	}, opts)
+}
+
+// Provide3 registers a constructor of the type T with three dependencies.
+func Provide3[T, A, B, C any](c *Container, fn func(A, B, C) (T, error), opts ...Option) {
+	provide[T](c, func(r *resolver) (interface{}, error) {
+		a, err := resolveParam[A](r, 0)
+		if err != nil {
+			return nil, err
+		}
+		b, err := resolveParam[B](r, 1)
+		if err != nil {
+			return nil, err
+		}
+		c, err := resolveParam[C](r, 2)
+		if err != nil {
+			return nil, err
+		}
+		return fn(a, b, c)
+	}, opts)
+}
+
+// Provide4 registers a constructor of the type T with four dependencies.
+func Provide4[T, A, B, C, D any](c *Container, fn func(A, B, C, D) (T, error), opts ...Option) {
+	provide[T](c, func(r *resolver) (interface{}, error) {
+		a, err := resolveParam[A](r, 0)
+		if err != nil {
+			return nil, err
+		}
+		b, err := resolveParam[B](r, 1)
+		if err != nil {
+			return nil, err
+		}
+		c, err := resolveParam[C](r, 2)
+		if err != nil {
+			return nil, err
+		}
+		d, err := resolveParam[D](r, 3)
+		if err != nil {
+			return nil, err
+		}
+		return fn(a, b, c, d)
+	}, opts)
+}
@@ -7,6 +7,8 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+
+	assist "github.com/nooize/go-assist"
 )
 
 /*
@@ -22,12 +24,51 @@ import (
 type Service struct {
 	Unit
 	Name string
-	quit chan os.Signal
-	shutdown chan error
+	// StartTimeout limits the time for each unit to start.
+	StartTimeout time.Duration
+	// StopTimeout limits the time for each unit to stop.
+	StopTimeout time.Duration
+	quit        chan os.Signal
+	shutdown    chan error
 }
 
-func (s *Service) Start() {
-	s.Unit.run()
+// Start validates the graph of units, starts all units the service depends on, independent units
+// concurrently, and blocks until the service is shut down by a signal or by Shutdown, then stops
+// the units in reverse dependency order. Units already started are rolled back the same way when
+// one of the units fails to start. SIGINT, SIGTERM, SIGQUIT and SIGHUP shut the service down, unless
+// reserved with assist.ReserveSignals, e.g. SIGHUP by env.Provider.ReloadOnSignal. Returns the suggested
+// exit code and the start, shutdown and stop errors, so the caller decides how to exit:
+//
+//	code, err := svc.Start()
+//	if err != nil {
+//		log.Print(err)
+//	}
+//	os.Exit(code)
+func (s *Service) Start() (int, error) {
+	if err := s.Unit.Validate(); err != nil {
+		return 1, err
+	}
+	if err := s.Unit.run(context.Background(), s.StartTimeout); err != nil {
+		errs := assist.NewMultiError(err)
+		errs.Append(s.Unit.stopAfter(s.StopTimeout))
+		return 1, errs.HasError()
+	}
+	code, err := s.run()
+	errs := assist.NewMultiError(err)
+	errs.Append(s.Unit.stopAfter(s.StopTimeout))
+	if err = errs.HasError(); err != nil && code == 0 {
+		code = 1
+	}
+	return code, err
+}
+
+// Shutdown asks the running service to stop. The error is returned by Start, a nil error means
+// normal shutdown. Only the first call has effect.
+func (s *Service) Shutdown(err error) {
+	select {
+	case s.shutdown <- err:
+	default:
+	}
 }
 
 func (s *Service) After(d *Unit) *Service {
@@ -35,81 +76,50 @@ func (s *Service) After(d *Unit) *Service {
 	return s
 }
 
-func (s *Service) run() error {
-
-	signal.Notify(s.quit, os.Interrupt, syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL, syscall.SIGHUP, syscall.SIGQUIT)
+// run waits for a quit signal or a shutdown request and returns the exit code with the shutdown error.
+func (s *Service) run() (int, error) {
+	signal.Notify(s.quit, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT, syscall.SIGHUP)
+	defer signal.Stop(s.quit)
 
 	log.Printf(s.Name + " is up.")
-	// logs.Logs().Info().Msgf("%v is up", core.ProductName)
-
 	defer func() {
 		log.Printf(s.Name + " is stop.")
-		// logs.Logs().Info().Msgf("%v stop", core.ProductName)
 	}()
 
-	_, cancelFunc := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancelFunc()
-
-
-	<- s.quit
-		//logging.Info("* Service is shutting down.. *")
-		//telemetry.ServerStop()
-		//appstatus.Instance.Idle = true
-		//cancel()
-		//appconfig.Instance.Close()
-	time.Sleep(1 * time.Second)
-	os.Exit(0)
-
-/*
 	for {
 		select {
-		case sig := <- s.quit:
-			switch sig {
-			case syscall.SIGINT:
-				// s.Shutdown()
-				os.Exit(0)
-			case syscall.SIGTERM:
-				// s.Shutdown()
-				os.Exit(143)
-			case syscall.SIGUSR1:
-				// File log re-open for rotating file logs.
-				// s.log.ReopenLogFile()
-			case syscall.SIGHUP:
-				//s.mu.Lock()
-				//ns := s.natsServer
-				//s.mu.Unlock()
-				//if ns != nil {
-				//	if err := ns.Reload(); err != nil {
-				//		s.log.Errorf("Reload: %v", err)
-				//	}
-				//} else {
-				//	s.log.Warnf("Reload supported only for embedded NATS Server's configuration")
-				//}
+		case sig := <-s.quit:
+			if assist.IsSignalReserved(sig) {
+				continue
 			}
-		case <- s.shutdown:
-			return nil
+			if n, ok := sig.(syscall.Signal); ok && sig != os.Interrupt {
+				return 128 + int(n), nil
+			}
+			return 0, nil
+		case err := <-s.shutdown:
+			if err != nil {
+				return 1, err
+			}
+			return 0, nil
 		}
 	}
-
-	os.Exit(0)
-*/
-	return nil
 }
 
 func NewService(name string) *Service {
 	s := Service{
-		quit: make(chan os.Signal, 1),
-		shutdown: make(chan error, 1),
-		Name: name,
+		quit:         make(chan os.Signal, 1),
+		shutdown:     make(chan error, 1),
+		Name:         name,
+		StartTimeout: DefaultStartTimeout,
+		StopTimeout:  DefaultStopTimeout,
 	}
 	s.Unit = *NewUnit(
 		func(state chan *UnitState) {
-			err := s.run()
-			state <- &UnitState{ Error: err }
+			state <- &UnitState{}
 		},
 		func(state chan *UnitState) {
-			os.Exit(0)
+			state <- &UnitState{}
 		},
-	)
+	).Named(name)
 	return &s
 }
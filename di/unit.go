@@ -1,8 +1,14 @@
 package di
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"time"
+
+	assist "github.com/nooize/go-assist"
+	"github.com/nooize/go-assist/lifecycle"
 )
 
 /*
@@ -15,6 +21,20 @@ import (
 
 */
 
+const (
+	// DefaultStartTimeout limits the time for each unit to start, unless set otherwise.
+	DefaultStartTimeout = 15 * time.Second
+	// DefaultStopTimeout limits the time for each unit to stop, unless set otherwise.
+	DefaultStopTimeout = 5 * time.Second
+)
+
+var (
+	// ErrStopTimeout is returned when the unit Stop handler does not report its state in time.
+	ErrStopTimeout = errors.New("unit stop timeout")
+	// ErrNotRunning is returned by IsReady of the unit adapter when the unit is not running.
+	ErrNotRunning = errors.New("unit is not running")
+)
+
 type UnitState struct {
 	Error error
 }
@@ -27,11 +47,14 @@ type UnitHandler struct {
 }
 
 type Unit struct {
+	name      string
 	lock      sync.Mutex
-	instance  *UnitHandler
+	instance  lifecycle.Unit
 	state     *UnitState
+	running   bool
 	dependers []*Unit
 	after     []*Unit
+	hooks     Hooks
 }
 
 func (m *Unit) After(d *Unit) *Unit {
@@ -58,51 +81,196 @@ func (m *Unit) After(d *Unit) *Unit {
 	return m
 }
 
-func (m *Unit) run() {
+// run starts units the unit depends on and then the unit itself. Independent dependencies are started
+// concurrently, every start is limited by the timeout. The first start error is returned, dependants of
+// the failed unit are not started.
+func (m *Unit) run(ctx context.Context, timeout time.Duration) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 	if m.state != nil {
-		return
+		return m.state.Error
 	}
 
-	for _, u := range m.after {
-		u.run()
+	if err := runAll(ctx, timeout, m.after); err != nil {
+		return err
 	}
 
-	stateChannel := make(chan *UnitState)
-	go m.instance.Start(stateChannel)
-	m.state = <-stateChannel
-	if m.state.Error != nil {
-		log.Printf("run error %v", m.state.Error)
-		// TODO log error
+	m.hooks.starting(m)
+	begin := time.Now()
+	defer func() {
+		m.hooks.started(m, time.Since(begin), m.state.Error)
+	}()
+
+	startCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// buffered, so the abandoned start is able to report its state and exit
+	stateChannel := make(chan *UnitState, 1)
+	go func() {
+		stateChannel <- &UnitState{Error: m.instance.Start(startCtx)}
+	}()
+	select {
+	case m.state = <-stateChannel:
+	case <-startCtx.Done():
+		err := startCtx.Err()
+		if m.name != "" {
+			err = fmt.Errorf("unit %s start : %w", m.name, err)
+		}
+		m.state = &UnitState{Error: err}
+		go m.abandon(stateChannel)
 	}
+	m.running = m.state.Error == nil
+	return m.state.Error
+}
 
+// runAll starts units concurrently and waits for all of them. The first error cancels starts
+// which are still in progress and is returned.
+func runAll(ctx context.Context, timeout time.Duration, units []*Unit) error {
+	if len(units) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+	)
+	for _, u := range units {
+		wg.Add(1)
+		go func(u *Unit) {
+			defer wg.Done()
+			if err := u.run(ctx, timeout); err != nil {
+				once.Do(func() {
+					first = err
+					cancel()
+				})
+			}
+		}(u)
+	}
+	wg.Wait()
+	return first
 }
 
-func (m *Unit) stop() {
+// abandon waits for the state of the start which exceeded its deadline and stops the unit
+// if it has started after all.
+func (m *Unit) abandon(stateChannel chan *UnitState) {
+	if state := <-stateChannel; state.Error == nil {
+		_ = m.instance.Stop()
+	}
+}
+
+// stop stops the running unit. The Stop handler which does not report its state within the timeout
+// is abandoned and ErrStopTimeout is returned.
+func (m *Unit) stop(timeout time.Duration) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
-	if m.state == nil {
-		return
+	if !m.running {
+		return nil
 	}
+	m.running = false
 
-	stateChannel := make(chan *UnitState)
-	go m.instance.Stop(stateChannel)
-	m.state = <-stateChannel
-	if m.state.Error != nil {
-		// TODO log error
+	m.hooks.stopping(m)
+	begin := time.Now()
+	// buffered, so the abandoned stop is able to report its state and exit
+	stateChannel := make(chan *UnitState, 1)
+	go func() {
+		stateChannel <- &UnitState{Error: m.instance.Stop()}
+	}()
+	var err error
+	select {
+	case m.state = <-stateChannel:
+		err = m.state.Error
+	case <-time.After(timeout):
+		err = ErrStopTimeout
+	}
+	m.hooks.stopped(m, time.Since(begin), err)
+	if err != nil && m.name != "" {
+		err = fmt.Errorf("unit %s stop : %w", m.name, err)
 	}
+	return err
+}
+
+// stopAfter stops all units the unit depends on, directly or transitively, in reverse dependency order:
+// every dependant is stopped before the units it depends on. Errors of all units are collected.
+func (m *Unit) stopAfter(timeout time.Duration) error {
+	order := m.startOrder()
+	// the unit itself goes last in start order
+	return stopUnits(order[:len(order)-1], timeout)
+}
+
+// stopAll stops the unit and then all units it depends on, like stopAfter.
+func (m *Unit) stopAll(timeout time.Duration) error {
+	return stopUnits(m.startOrder(), timeout)
+}
 
-	for _, u := range m.after {
-		u.stop()
+// stopUnits stops units in reverse order and collects their errors.
+func stopUnits(order []*Unit, timeout time.Duration) error {
+	errs := assist.NewMultiError(nil)
+	for i := len(order) - 1; i >= 0; i-- {
+		errs.Append(order[i].stop(timeout))
 	}
+	return errs.HasError()
+}
 
+// startOrder returns the unit and all units it depends on, every unit goes after its dependencies.
+func (m *Unit) startOrder() []*Unit {
+	order := make([]*Unit, 0)
+	visited := make(map[*Unit]bool)
+	var visit func(u *Unit)
+	visit = func(u *Unit) {
+		if visited[u] {
+			return
+		}
+		visited[u] = true
+		for _, d := range u.after {
+			visit(d)
+		}
+		order = append(order, u)
+	}
+	visit(m)
+	return order
 }
 
+// HandlerToUnit adapts the channel based handler to the lifecycle core.
 func HandlerToUnit(i *UnitHandler) *Unit {
+	return FromLifecycle(handlerUnit{i})
+}
+
+// FromLifecycle returns the unit running the lifecycle, e.g. apx.ApxUnit, so it can be used as a dependency
+// of di units.
+func FromLifecycle(l lifecycle.Unit) *Unit {
 	return &Unit{
-		instance: i,
+		instance: l,
+	}
+}
+
+// handlerUnit runs channel based handlers as the lifecycle, it is always ready.
+type handlerUnit struct {
+	h *UnitHandler
+}
+
+func (u handlerUnit) Start(ctx context.Context) error {
+	return u.call(u.h.Start)
+}
+
+func (u handlerUnit) IsReady(ctx context.Context) error {
+	return nil
+}
+
+func (u handlerUnit) Stop() error {
+	return u.call(u.h.Stop)
+}
+
+func (u handlerUnit) call(fn func(chan *UnitState)) error {
+	if fn == nil {
+		return nil
+	}
+	state := make(chan *UnitState, 1)
+	go fn(state)
+	if s := <-state; s != nil {
+		return s.Error
 	}
+	return nil
 }
 
 func NewUnit(start, stop func(chan *UnitState)) *Unit {
@@ -0,0 +1,177 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeUnit runs the start function and counts successful starts and stops.
+type fakeUnit struct {
+	start   func(ctx context.Context) error
+	started int32
+	stopped int32
+}
+
+func (f *fakeUnit) Start(ctx context.Context) error {
+	var err error
+	if f.start != nil {
+		err = f.start(ctx)
+	}
+	if err == nil {
+		atomic.AddInt32(&f.started, 1)
+	}
+	return err
+}
+
+func (f *fakeUnit) IsReady(context.Context) error {
+	return nil
+}
+
+func (f *fakeUnit) Stop() error {
+	atomic.AddInt32(&f.stopped, 1)
+	return nil
+}
+
+func (f *fakeUnit) counts() (started, stopped int32) {
+	return atomic.LoadInt32(&f.started), atomic.LoadInt32(&f.stopped)
+}
+
+func sleepStart(d time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		select {
+		case <-time.After(d):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func TestStartIndependentUnitsConcurrently(t *testing.T) {
+	aIn, bIn := make(chan struct{}), make(chan struct{})
+	// each unit waits for the other one, so sequential start hits the timeout
+	meet := func(in, other chan struct{}) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			close(in)
+			select {
+			case <-other:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	a := &fakeUnit{start: meet(aIn, bIn)}
+	b := &fakeUnit{start: meet(bIn, aIn)}
+	web := &fakeUnit{}
+	root := FromLifecycle(web).After(FromLifecycle(a)).After(FromLifecycle(b))
+	if err := root.StartAll(context.Background(), time.Second); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	for name, u := range map[string]*fakeUnit{"a": a, "b": b, "web": web} {
+		if started, _ := u.counts(); started != 1 {
+			t.Errorf("%s : started %d times", name, started)
+		}
+	}
+}
+
+func TestStartTimeoutIsPerUnit(t *testing.T) {
+	units := make([]*fakeUnit, 3)
+	var root *Unit
+	for i := range units {
+		units[i] = &fakeUnit{start: sleepStart(60 * time.Millisecond)}
+		u := FromLifecycle(units[i])
+		if root != nil {
+			u.After(root)
+		}
+		root = u
+	}
+	if err := root.StartAll(context.Background(), 200*time.Millisecond); err != nil {
+		t.Fatalf("chain longer than the timeout fails : %v", err)
+	}
+}
+
+func TestStartTimeout(t *testing.T) {
+	slow := &fakeUnit{start: func(context.Context) error {
+		// ignores the context and starts after all
+		time.Sleep(150 * time.Millisecond)
+		return nil
+	}}
+	web := &fakeUnit{}
+	root := FromLifecycle(web).After(FromLifecycle(slow).Named("slow"))
+	err := root.StartAll(context.Background(), 50*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "unit slow start") {
+		t.Fatalf("expected deadline error of the slow unit, got %v", err)
+	}
+	if started, _ := web.counts(); started != 0 {
+		t.Fatal("dependant of the timed out unit is started")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if started, stopped := slow.counts(); started == 1 && stopped == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("abandoned unit is not stopped after its late start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStartFailureCancelsSiblings(t *testing.T) {
+	failure := errors.New("no connection")
+	siblingErr := make(chan error, 1)
+	failing := &fakeUnit{start: func(context.Context) error { return failure }}
+	sibling := &fakeUnit{start: func(ctx context.Context) error {
+		err := sleepStart(5 * time.Second)(ctx)
+		siblingErr <- err
+		return err
+	}}
+	root := FromLifecycle(&fakeUnit{}).After(FromLifecycle(failing)).After(FromLifecycle(sibling))
+	begin := time.Now()
+	if err := root.StartAll(context.Background(), 10*time.Second); !errors.Is(err, failure) {
+		t.Fatalf("expected start failure, got %v", err)
+	}
+	if time.Since(begin) > time.Second {
+		t.Fatal("start failure waits for the sibling")
+	}
+	if err := <-siblingErr; err != context.Canceled {
+		t.Fatalf("start of the sibling is not canceled : %v", err)
+	}
+}
+
+func TestServiceRollsBackStartedUnits(t *testing.T) {
+	failure := errors.New("no connection")
+	db := &fakeUnit{}
+	cache := &fakeUnit{}
+	failing := &fakeUnit{start: func(context.Context) error { return failure }}
+	svc := NewService("svc")
+	svc.StartTimeout, svc.StopTimeout = time.Second, time.Second
+	svc.After(FromLifecycle(failing).After(FromLifecycle(db)).After(FromLifecycle(cache)))
+
+	code, err := svc.Start()
+	if code != 1 || err == nil || !strings.Contains(err.Error(), failure.Error()) {
+		t.Fatalf("got %d, %v", code, err)
+	}
+	tests := []struct {
+		name string
+		unit *fakeUnit
+	}{
+		{"db", db},
+		{"cache", cache},
+		{"failing", failing},
+	}
+	for _, tt := range tests {
+		want := int32(1)
+		if tt.unit == failing {
+			want = 0
+		}
+		if started, stopped := tt.unit.counts(); started != want || stopped != want {
+			t.Errorf("%s : started %d times, stopped %d times, want %d", tt.name, started, stopped, want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ Configuration documentation and dump
+
+ type Config struct {
+    Url      *url.URL `env:"DB_URL" required:"true" description:"database connection"`
+    Password Secret   `env:"DB_PASSWORD"`
+ }
+
+ env.WriteMarkdown(os.Stdout, &Config{})        // table of variables
+ env.WriteDotEnvExample(os.Stdout, &Config{})   // .env.example
+ http.Handle("/debug/config", env.DumpHandler(&cfg))
+*/
+
+const (
+	tagDescription = "description"
+	tagSecret      = "secret"
+)
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// isSecret reports whether the field is of the type Secret or tagged with secret:"true".
+func isSecret(field reflect.StructField) bool {
+	secret, _ := strconv.ParseBool(field.Tag.Get(tagSecret))
+	return secret || field.Type == secretType || field.Type == reflect.PtrTo(secretType)
+}
+
+// Var describes a configuration variable.
+type Var struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required"`
+	Description string `json:"description,omitempty"`
+	// Secret variables are of the type Secret or tagged with secret:"true", their values and defaults are masked.
+	Secret bool `json:"secret"`
+	// Value is the effective value, masked for secrets, set by Dump only.
+	Value string `json:"value"`
+	// Origin is the name of the source of the value, set by Dump only.
+	Origin string `json:"origin,omitempty"`
+}
+
+// Describe lists variables of the configuration struct, see Load for supported tags. The tag
+// description documents the variable.
+func Describe(v interface{}) ([]Var, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("env: Describe expects a struct or a pointer to struct")
+	}
+	vars := make([]Var, 0)
+	walkFields(rv, "", false, func(key string, field reflect.StructField, fv reflect.Value) {
+		v := Var{
+			Key:         key,
+			Type:        field.Type.String(),
+			Default:     field.Tag.Get(tagDefault),
+			Required:    isRequired(field),
+			Description: field.Tag.Get(tagDescription),
+			Secret:      isSecret(field),
+		}
+		if v.Secret && v.Default != "" {
+			v.Default = redacted
+		}
+		vars = append(vars, v)
+	})
+	return vars, nil
+}
+
+// Dump lists variables of the loaded configuration struct with their effective values and the sources
+// of the default provider they came from. Values of secrets are masked, passwords are removed from URLs.
+func Dump(v interface{}) ([]Var, error) {
+	vars, err := Describe(v)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	walkFields(reflect.Indirect(reflect.ValueOf(v)), "", false, func(key string, field reflect.StructField, fv reflect.Value) {
+		values[key] = formatValue(fv)
+	})
+	p := DefaultProvider()
+	for i := range vars {
+		vars[i].Value = values[vars[i].Key]
+		if vars[i].Secret && vars[i].Value != "" {
+			vars[i].Value = redacted
+		}
+		vars[i].Origin = p.Origin(vars[i].Key)
+		if vars[i].Origin == "" && p.Origin(vars[i].Key+FileSuffix) != "" {
+			vars[i].Origin = "file"
+		}
+		if vars[i].Origin == "" && vars[i].Default != "" {
+			vars[i].Origin = "default"
+		}
+	}
+	return vars, nil
+}
+
+// DumpHandler serves Dump of the configuration struct as JSON.
+func DumpHandler(v interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars, err := Dump(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_ = json.NewEncoder(w).Encode(vars)
+	})
+}
+
+// WriteMarkdown prints the table of variables of the configuration struct.
+func WriteMarkdown(w io.Writer, v interface{}) error {
+	vars, err := Describe(v)
+	if err != nil {
+		return err
+	}
+	escape := strings.NewReplacer("|", "\\|", "\n", " ").Replace
+	if _, err := fmt.Fprintln(w, "| Variable | Type | Default | Required | Description |"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "|---|---|---|---|---|"); err != nil {
+		return err
+	}
+	for _, v := range vars {
+		required := ""
+		if v.Required {
+			required = "yes"
+		}
+		def := ""
+		if v.Default != "" {
+			def = "`" + escape(v.Default) + "`"
+		}
+		if _, err := fmt.Fprintf(w, "| `%s` | `%s` | %s | %s | %s |\n",
+			v.Key, v.Type, def, required, escape(v.Description)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteDotEnvExample prints variables of the configuration struct in .env form, with defaults as values
+// and descriptions as comments. Secrets and required variables without default are left empty.
+func WriteDotEnvExample(w io.Writer, v interface{}) error {
+	vars, err := Describe(v)
+	if err != nil {
+		return err
+	}
+	for i, v := range vars {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		comment := v.Type
+		if v.Required {
+			comment += ", required"
+		}
+		if v.Description != "" {
+			comment = v.Description + " (" + comment + ")"
+		}
+		value := v.Default
+		if v.Secret {
+			value = ""
+		}
+		if _, err := fmt.Fprintf(w, "# %s\n%s=%s\n", comment, v.Key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatValue(v reflect.Value) string {
+	if v.Type() == locationType && !v.IsNil() {
+		return v.Interface().(*time.Location).String()
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch v.Type() {
+	case urlType:
+		u := v.Interface().(url.URL)
+		return u.Redacted()
+	case ipNetType:
+		// String is declared on the pointer
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return fmt.Sprint(ptr.Interface())
+	}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Map {
+		if v.Len() == 0 {
+			return ""
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
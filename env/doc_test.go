@@ -0,0 +1,64 @@
+package env
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type docNested struct {
+	Host string `env:"HOST" default:"localhost"`
+}
+
+type docConfig struct {
+	Password Secret     `env:"DOC_PASSWORD" default:"hunter2"`
+	Token    string     `env:"DOC_TOKEN" default:"t0ken" secret:"true"`
+	Port     int        `env:"DOC_PORT" default:"8080"`
+	N        *docNested `prefix:"DOC_N_"`
+}
+
+func TestDumpKeepsConfigUntouched(t *testing.T) {
+	var cfg docConfig
+	vars, err := Dump(&cfg)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	if cfg.N != nil {
+		t.Fatal("Dump allocates nested struct of the config")
+	}
+	if _, err = Describe(&cfg); err != nil || cfg.N != nil {
+		t.Fatalf("Describe allocates nested struct of the config : %v", err)
+	}
+	keys := make([]string, len(vars))
+	for i, v := range vars {
+		keys[i] = v.Key
+	}
+	if got := strings.Join(keys, ","); got != "DOC_PASSWORD,DOC_TOKEN,DOC_PORT,DOC_N_HOST" {
+		t.Fatalf("unexpected variables : %s", got)
+	}
+}
+
+func TestSecretDefaultsAreMasked(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteMarkdown(&out, &docConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteDotEnvExample(&out, &docConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	vars, err := Dump(&docConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vars {
+		out.WriteString(v.Default + " " + v.Value + "\n")
+	}
+	for _, secret := range []string{"hunter2", "t0ken"} {
+		if strings.Contains(out.String(), secret) {
+			t.Fatalf("secret default %q is published :\n%s", secret, out.String())
+		}
+	}
+	if !strings.Contains(out.String(), "8080") {
+		t.Fatalf("default of the plain variable is missing :\n%s", out.String())
+	}
+}
@@ -0,0 +1,87 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrRequired is reported for required variables which are not set.
+var ErrRequired = errors.New("required variable is not set")
+
+// VarError describes a bad or missing variable. The error is safe to log: Value is empty for secrets
+// and passwords of URLs are masked.
+type VarError struct {
+	Key   string
+	Value string
+	Err   error
+}
+
+func (e *VarError) Error() string {
+	if e.Value == "" {
+		return e.Key + " : " + e.Err.Error()
+	}
+	return fmt.Sprintf("%s=%q : %s", e.Key, e.Value, e.Err.Error())
+}
+
+func (e *VarError) Unwrap() error {
+	return e.Err
+}
+
+// malformed returns the VarError of the value which fails to parse. The value of the secret is omitted
+// with the message of its parse error, which may quote it.
+func malformed(key, value string, err error, secret bool) *VarError {
+	if secret {
+		return &VarError{Key: key, Err: secretError{err}}
+	}
+	// url.Error quotes the whole value
+	if ue, ok := err.(*url.Error); ok {
+		err = ue.Err
+	}
+	return &VarError{Key: key, Value: redactURL(value), Err: err}
+}
+
+// secretError hides the message of the error, the error itself is still available to errors.Is and errors.As.
+type secretError struct {
+	err error
+}
+
+func (e secretError) Error() string {
+	return "malformed secret value"
+}
+
+func (e secretError) Unwrap() error {
+	return e.err
+}
+
+// redactURL masks the password of the URL like value. The value is malformed, so it is not parsed:
+// everything up to the last '@' after the scheme is taken as user info, unless the user name has
+// a path or a query in it.
+func redactURL(s string) string {
+	i := strings.Index(s, "://")
+	if i < 0 {
+		return s
+	}
+	rest := s[i+3:]
+	at := strings.LastIndex(rest, "@")
+	if at < 0 {
+		return s
+	}
+	user, _, hasPassword := strings.Cut(rest[:at], ":")
+	if !hasPassword || strings.ContainsAny(user, "/?#") {
+		return s
+	}
+	return s[:i+3] + user + ":" + redacted + rest[at:]
+}
+
+// Errors lists every bad or missing variable.
+type Errors []*VarError
+
+func (e Errors) Error() string {
+	formatted := make([]string, len(e))
+	for i, err := range e {
+		formatted[i] = err.Error()
+	}
+	return fmt.Sprintf("env: %d bad variable(s) : %s", len(e), strings.Join(formatted, "; "))
+}
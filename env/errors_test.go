@@ -0,0 +1,74 @@
+package env
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type errorsConfig struct {
+	DSN     url.URL `env:"ERR_DSN"`
+	Pin     int     `env:"ERR_PIN" secret:"true"`
+	Retries int     `env:"ERR_RETRIES"`
+}
+
+func TestVarErrorHidesSecrets(t *testing.T) {
+	p := NewProvider(NewMapSource("test", map[string]string{
+		"ERR_DSN":     "postgres://admin:hunter2@db:port/app",
+		"ERR_PIN":     "hunter2",
+		"ERR_RETRIES": "three",
+	}))
+	err := p.Load(&errorsConfig{})
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Fatalf("expected 3 variable errors, got %v", err)
+	}
+	msg := err.Error()
+	if strings.Contains(msg, "hunter2") {
+		t.Fatalf("secret is published : %s", msg)
+	}
+	for _, want := range []string{`postgres://admin:` + redacted + `@db:port/app`, "ERR_PIN : malformed secret value", `"three"`} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("%q is missing in %s", want, msg)
+		}
+	}
+	if !errors.Is(errs[1], strconv.ErrSyntax) {
+		t.Errorf("parse error of the secret is lost : %v", errs[1].Err)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"postgres://admin:hunter2@db/app", "postgres://admin:" + redacted + "@db/app"},
+		{"postgres://admin:p@ss:w0rd@db/app", "postgres://admin:" + redacted + "@db/app"},
+		{"postgres://admin:p/ss#?@db/app", "postgres://admin:" + redacted + "@db/app"},
+		{"postgres://admin@db/app", "postgres://admin@db/app"},
+		{"http://host/path?q=a:b@c", "http://host/path?q=a:b@c"},
+		{"user:pass@host", "user:pass@host"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := redactURL(tt.in); got != tt.want {
+			t.Errorf("%q : got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGetUrlLogsRedacted(t *testing.T) {
+	var out bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&out)
+	t.Setenv(testKey, "redis://:hunter2@cache:port")
+	if u := GetUrl(testKey, ""); u != nil {
+		t.Fatalf("malformed url is parsed : %v", u)
+	}
+	if strings.Contains(out.String(), "hunter2") || !strings.Contains(out.String(), testKey) {
+		t.Fatalf("unexpected log : %s", out.String())
+	}
+}
@@ -1,14 +1,17 @@
 package env
 
 import (
+	"errors"
 	"log"
 	"net/url"
-	"os"
 	"strconv"
-	"strings"
 	"time"
 )
 
+// FileSuffix is appended to the variable name to get the name of the variable with the path to the file
+// holding the value, see Provider.Read.
+const FileSuffix = "_FILE"
+
 func GetStr(key, def string) string {
 	if v := getEnv(key); len(v) > 0 {
 		return v
@@ -17,41 +20,135 @@ func GetStr(key, def string) string {
 }
 
 func GetUrl(key, def string) *url.URL {
-	v := getEnv(key)
-	if v == "" {
-		v = def
-	}
-	url, err := url.Parse(v)
+	url, err := LookupUrl(key, def)
 	if err != nil {
-		log.Printf("bad url (%s) in env key %s", v, key)
+		log.Printf("bad url in env key %s : %v", key, err)
 		return nil
 	}
 	return url
 }
 
 func GetInt(key string, def int) int {
-	i, err := strconv.Atoi(getEnv(key))
-	if err != nil {
-		return def
-	}
+	i, _ := LookupInt(key, def)
 	return i
 }
 
 func GetPositiveInt(key string, def int) int {
-	i :=  GetInt(key, def)
-	if i < 0 {
-		return def
-	}
+	i, _ := LookupPositiveInt(key, def)
 	return i
 }
 
 func GetDuration(key string, def time.Duration) time.Duration {
-	if d, _ := time.ParseDuration(getEnv(key)); d > 0 {
-		return d
+	d, _ := LookupDuration(key, def)
+	return d
+}
+
+// LookupUrl returns the url from the variable, or parsed default when the variable is not set.
+func LookupUrl(key, def string) (*url.URL, error) {
+	return lookupUrl(readEnv, key, def)
+}
+
+func lookupUrl(read func(key string) (string, error), key, def string) (*url.URL, error) {
+	v, err := read(key)
+	if err != nil {
+		return nil, err
 	}
-	return def
+	if v == "" {
+		v = def
+	}
+	u, err := url.Parse(v)
+	if err != nil {
+		return nil, malformed(key, v, err, false)
+	}
+	return u, nil
+}
+
+// LookupInt returns the default when the variable is not set and VarError when it is malformed.
+func LookupInt(key string, def int) (int, error) {
+	return lookup(key, def, parseInt)
+}
+
+// LookupPositiveInt is like LookupInt, negative values are reported as malformed.
+func LookupPositiveInt(key string, def int) (int, error) {
+	return lookup(key, def, parsePositiveInt)
+}
+
+// LookupDuration is like LookupInt, zero and negative durations are reported as malformed.
+func LookupDuration(key string, def time.Duration) (time.Duration, error) {
+	return lookup(key, def, parseDuration)
+}
+
+// MustUrl is like LookupUrl but panics when the variable is malformed.
+func MustUrl(key, def string) *url.URL {
+	return must(LookupUrl(key, def))
+}
+
+// MustInt is like LookupInt but panics when the variable is malformed.
+func MustInt(key string, def int) int {
+	return must(LookupInt(key, def))
+}
+
+// MustPositiveInt is like LookupPositiveInt but panics when the variable is malformed.
+func MustPositiveInt(key string, def int) int {
+	return must(LookupPositiveInt(key, def))
+}
+
+// MustDuration is like LookupDuration but panics when the variable is malformed.
+func MustDuration(key string, def time.Duration) time.Duration {
+	return must(LookupDuration(key, def))
+}
+
+func lookup[T any](key string, def T, parse func(string) (T, error)) (T, error) {
+	return lookupWith(readEnv, key, def, parse)
+}
+
+func lookupWith[T any](read func(key string) (string, error), key string, def T, parse func(string) (T, error)) (T, error) {
+	v, err := read(key)
+	if err != nil {
+		return def, err
+	}
+	if v == "" {
+		return def, nil
+	}
+	t, err := parse(v)
+	if err != nil {
+		return def, malformed(key, v, err, false)
+	}
+	return t, nil
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func parseInt(s string) (int, error) {
+	return strconv.Atoi(s)
+}
+
+func parsePositiveInt(s string) (int, error) {
+	i, err := strconv.Atoi(s)
+	if err == nil && i < 0 {
+		err = errors.New("must not be negative")
+	}
+	return i, err
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err == nil && d <= 0 {
+		err = errors.New("must be positive")
+	}
+	return d, err
 }
 
 func getEnv(key string) string {
-	return strings.TrimSpace(os.Getenv(key))
+	v, _ := readEnv(key)
+	return v
+}
+
+func readEnv(key string) (string, error) {
+	return DefaultProvider().read(key)
 }
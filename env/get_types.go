@@ -0,0 +1,250 @@
+package env
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func GetBool(key string, def bool) bool {
+	v, _ := LookupBool(key, def)
+	return v
+}
+
+func GetFloat(key string, def float64) float64 {
+	v, _ := LookupFloat(key, def)
+	return v
+}
+
+func GetUint(key string, def uint64) uint64 {
+	v, _ := LookupUint(key, def)
+	return v
+}
+
+func GetBytes(key string, def ByteSize) ByteSize {
+	v, _ := LookupBytes(key, def)
+	return v
+}
+
+// GetStrList returns comma separated items, empty items are skipped.
+func GetStrList(key string, def []string) []string {
+	v, _ := LookupStrList(key, def)
+	return v
+}
+
+// GetIntList returns comma separated integers.
+func GetIntList(key string, def []int) []int {
+	v, _ := LookupIntList(key, def)
+	return v
+}
+
+// GetMap returns comma separated key=val pairs.
+func GetMap(key string, def map[string]string) map[string]string {
+	v, _ := LookupMap(key, def)
+	return v
+}
+
+func GetIP(key string, def net.IP) net.IP {
+	v, _ := LookupIP(key, def)
+	return v
+}
+
+// GetIPNet returns the network in CIDR notation, like 10.0.0.0/8.
+func GetIPNet(key string, def *net.IPNet) *net.IPNet {
+	v, _ := LookupIPNet(key, def)
+	return v
+}
+
+// GetLocation returns the time zone by IANA name, like Europe/Berlin.
+func GetLocation(key string, def *time.Location) *time.Location {
+	v, _ := LookupLocation(key, def)
+	return v
+}
+
+func GetLogLevel(key string, def LogLevel) LogLevel {
+	v, _ := LookupLogLevel(key, def)
+	return v
+}
+
+func LookupBool(key string, def bool) (bool, error) {
+	return lookup(key, def, ParseBool)
+}
+
+func LookupFloat(key string, def float64) (float64, error) {
+	return lookup(key, def, parseFloat)
+}
+
+func LookupUint(key string, def uint64) (uint64, error) {
+	return lookup(key, def, parseUint)
+}
+
+func LookupBytes(key string, def ByteSize) (ByteSize, error) {
+	return lookup(key, def, ParseByteSize)
+}
+
+func LookupStrList(key string, def []string) ([]string, error) {
+	return lookup(key, def, parseStrList)
+}
+
+func LookupIntList(key string, def []int) ([]int, error) {
+	return lookup(key, def, parseIntList)
+}
+
+func LookupMap(key string, def map[string]string) (map[string]string, error) {
+	return lookup(key, def, parseMap)
+}
+
+func LookupIP(key string, def net.IP) (net.IP, error) {
+	return lookup(key, def, parseIP)
+}
+
+func LookupIPNet(key string, def *net.IPNet) (*net.IPNet, error) {
+	return lookup(key, def, parseIPNet)
+}
+
+func LookupLocation(key string, def *time.Location) (*time.Location, error) {
+	return lookup(key, def, time.LoadLocation)
+}
+
+func LookupLogLevel(key string, def LogLevel) (LogLevel, error) {
+	return lookup(key, def, ParseLogLevel)
+}
+
+func MustBool(key string, def bool) bool {
+	return must(LookupBool(key, def))
+}
+
+func MustFloat(key string, def float64) float64 {
+	return must(LookupFloat(key, def))
+}
+
+func MustUint(key string, def uint64) uint64 {
+	return must(LookupUint(key, def))
+}
+
+func MustBytes(key string, def ByteSize) ByteSize {
+	return must(LookupBytes(key, def))
+}
+
+func MustStrList(key string, def []string) []string {
+	return must(LookupStrList(key, def))
+}
+
+func MustIntList(key string, def []int) []int {
+	return must(LookupIntList(key, def))
+}
+
+func MustMap(key string, def map[string]string) map[string]string {
+	return must(LookupMap(key, def))
+}
+
+func MustIP(key string, def net.IP) net.IP {
+	return must(LookupIP(key, def))
+}
+
+func MustIPNet(key string, def *net.IPNet) *net.IPNet {
+	return must(LookupIPNet(key, def))
+}
+
+func MustLocation(key string, def *time.Location) *time.Location {
+	return must(LookupLocation(key, def))
+}
+
+func MustLogLevel(key string, def LogLevel) LogLevel {
+	return must(LookupLogLevel(key, def))
+}
+
+func (r *Reader) Bool(key string, def bool) bool {
+	return read(r, key, def, ParseBool)
+}
+
+func (r *Reader) Float(key string, def float64) float64 {
+	return read(r, key, def, parseFloat)
+}
+
+func (r *Reader) Uint(key string, def uint64) uint64 {
+	return read(r, key, def, parseUint)
+}
+
+func (r *Reader) Bytes(key string, def ByteSize) ByteSize {
+	return read(r, key, def, ParseByteSize)
+}
+
+func (r *Reader) StrList(key string, def []string) []string {
+	return read(r, key, def, parseStrList)
+}
+
+func (r *Reader) IntList(key string, def []int) []int {
+	return read(r, key, def, parseIntList)
+}
+
+func (r *Reader) Map(key string, def map[string]string) map[string]string {
+	return read(r, key, def, parseMap)
+}
+
+func (r *Reader) IP(key string, def net.IP) net.IP {
+	return read(r, key, def, parseIP)
+}
+
+func (r *Reader) IPNet(key string, def *net.IPNet) *net.IPNet {
+	return read(r, key, def, parseIPNet)
+}
+
+func (r *Reader) Location(key string, def *time.Location) *time.Location {
+	return read(r, key, def, time.LoadLocation)
+}
+
+func (r *Reader) LogLevel(key string, def LogLevel) LogLevel {
+	return read(r, key, def, ParseLogLevel)
+}
+
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+func parseUint(s string) (uint64, error) {
+	return strconv.ParseUint(s, 10, 64)
+}
+
+func parseStrList(s string) ([]string, error) {
+	return split(s, defaultSeparator), nil
+}
+
+func parseIntList(s string) ([]int, error) {
+	items := split(s, defaultSeparator)
+	list := make([]int, len(items))
+	for i, item := range items {
+		v, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = v
+	}
+	return list, nil
+}
+
+func parseMap(s string) (map[string]string, error) {
+	m := make(map[string]string)
+	for _, item := range split(s, defaultSeparator) {
+		kv := strings.SplitN(item, defaultKvSeparator, 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("expect key%svalue, has: %s", defaultKvSeparator, item)
+		}
+		m[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return m, nil
+}
+
+func parseIP(s string) (net.IP, error) {
+	if ip := net.ParseIP(s); ip != nil {
+		return ip, nil
+	}
+	return nil, fmt.Errorf("expect IP address, has: %s", s)
+}
+
+func parseIPNet(s string) (*net.IPNet, error) {
+	_, n, err := net.ParseCIDR(s)
+	return n, err
+}
@@ -0,0 +1,230 @@
+package env
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testKey = "ENV_TEST_VALUE"
+
+func expectVarError(t *testing.T, value string, err error, bad bool) {
+	t.Helper()
+	var varErr *VarError
+	if bad != errors.As(err, &varErr) {
+		t.Fatalf("%q : unexpected error %v", value, err)
+	}
+}
+
+func TestLookupStrList(t *testing.T) {
+	def := []string{"default"}
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", def},
+		{"a", []string{"a"}},
+		{"a,b,c", []string{"a", "b", "c"}},
+		{" a , ,b ,", []string{"a", "b"}},
+		{",", []string{}},
+	}
+	for _, tt := range tests {
+		t.Setenv(testKey, tt.value)
+		got, err := LookupStrList(testKey, def)
+		expectVarError(t, tt.value, err, false)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q : got %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLookupIntList(t *testing.T) {
+	def := []int{7}
+	tests := []struct {
+		value string
+		want  []int
+		bad   bool
+	}{
+		{"", def, false},
+		{"1", []int{1}, false},
+		{"1, 2,-3", []int{1, 2, -3}, false},
+		{"010", []int{10}, false},
+		{"1,x", def, true},
+		{"0x10", def, true},
+	}
+	for _, tt := range tests {
+		t.Setenv(testKey, tt.value)
+		got, err := LookupIntList(testKey, def)
+		expectVarError(t, tt.value, err, tt.bad)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q : got %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLookupMap(t *testing.T) {
+	def := map[string]string{"k": "v"}
+	tests := []struct {
+		value string
+		want  map[string]string
+		bad   bool
+	}{
+		{"", def, false},
+		{"a=1", map[string]string{"a": "1"}, false},
+		{" a = 1 , b=2=3", map[string]string{"a": "1", "b": "2=3"}, false},
+		{"a=", map[string]string{"a": ""}, false},
+		{"a", def, true},
+		{"a=1,b", def, true},
+	}
+	for _, tt := range tests {
+		t.Setenv(testKey, tt.value)
+		got, err := LookupMap(testKey, def)
+		expectVarError(t, tt.value, err, tt.bad)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q : got %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLookupIP(t *testing.T) {
+	def := net.IPv4(127, 0, 0, 1)
+	tests := []struct {
+		value string
+		want  net.IP
+		bad   bool
+	}{
+		{"", def, false},
+		{"10.0.0.1", net.IPv4(10, 0, 0, 1), false},
+		{"::1", net.IPv6loopback, false},
+		{"10.0.0", def, true},
+		{"localhost", def, true},
+	}
+	for _, tt := range tests {
+		t.Setenv(testKey, tt.value)
+		got, err := LookupIP(testKey, def)
+		expectVarError(t, tt.value, err, tt.bad)
+		if !got.Equal(tt.want) {
+			t.Errorf("%q : got %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLookupIPNet(t *testing.T) {
+	_, def, _ := net.ParseCIDR("127.0.0.0/8")
+	tests := []struct {
+		value string
+		want  string
+		bad   bool
+	}{
+		{"", "127.0.0.0/8", false},
+		{"10.0.0.0/8", "10.0.0.0/8", false},
+		{"192.168.1.10/24", "192.168.1.0/24", false},
+		{"fd00::/8", "fd00::/8", false},
+		{"10.0.0.1", "127.0.0.0/8", true},
+		{"10.0.0.0/33", "127.0.0.0/8", true},
+	}
+	for _, tt := range tests {
+		t.Setenv(testKey, tt.value)
+		got, err := LookupIPNet(testKey, def)
+		expectVarError(t, tt.value, err, tt.bad)
+		if got.String() != tt.want {
+			t.Errorf("%q : got %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLookupLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no time zone database : %v", err)
+	}
+	tests := []struct {
+		value string
+		want  string
+		bad   bool
+	}{
+		{"", "Europe/Berlin", false},
+		{"UTC", "UTC", false},
+		{"Local", "Local", false},
+		{"America/New_York", "America/New_York", false},
+		{"Mars/Olympus", "Europe/Berlin", true},
+	}
+	for _, tt := range tests {
+		t.Setenv(testKey, tt.value)
+		got, err := LookupLocation(testKey, berlin)
+		expectVarError(t, tt.value, err, tt.bad)
+		if got.String() != tt.want {
+			t.Errorf("%q : got %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLookupLogLevel(t *testing.T) {
+	tests := []struct {
+		value string
+		want  LogLevel
+		bad   bool
+	}{
+		{"", LevelWarn, false},
+		{"debug", LevelDebug, false},
+		{"ERROR", LevelError, false},
+		{"warning", LevelWarn, false},
+		{"loud", LevelWarn, true},
+	}
+	for _, tt := range tests {
+		t.Setenv(testKey, tt.value)
+		got, err := LookupLogLevel(testKey, LevelWarn)
+		expectVarError(t, tt.value, err, tt.bad)
+		if got != tt.want {
+			t.Errorf("%q : got %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGetDefaults(t *testing.T) {
+	t.Setenv(testKey, "bad")
+	if got := GetBool(testKey, true); !got {
+		t.Errorf("GetBool : got %v, want the default", got)
+	}
+	if got := GetBytes(testKey, MiB); got != MiB {
+		t.Errorf("GetBytes : got %v, want the default", got)
+	}
+	if got := GetIntList(testKey, []int{1}); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("GetIntList : got %v, want the default", got)
+	}
+	if got := GetIP(testKey, net.IPv6loopback); !got.Equal(net.IPv6loopback) {
+		t.Errorf("GetIP : got %v, want the default", got)
+	}
+	if got := GetIPNet(testKey, nil); got != nil {
+		t.Errorf("GetIPNet : got %v, want the default", got)
+	}
+	if got := GetLocation(testKey, time.UTC); got != time.UTC {
+		t.Errorf("GetLocation : got %v, want the default", got)
+	}
+	if got := GetLogLevel(testKey, LevelError); got != LevelError {
+		t.Errorf("GetLogLevel : got %v, want the default", got)
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	var cfg struct {
+		Zone    *time.Location `env:"ENV_TEST_ZONE"`
+		UTC     *time.Location `env:"ENV_TEST_UTC" default:"UTC"`
+		Missing *time.Location `env:"ENV_TEST_MISSING"`
+	}
+	t.Setenv("ENV_TEST_ZONE", "Local")
+	if err := Load(&cfg); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	if cfg.Zone != time.Local {
+		t.Errorf("Local is loaded as a copy : %p, want %p", cfg.Zone, time.Local)
+	}
+	if cfg.UTC != time.UTC {
+		t.Errorf("UTC is loaded as a copy : %p, want %p", cfg.UTC, time.UTC)
+	}
+	if cfg.Missing != nil {
+		t.Errorf("not set location is loaded : %v", cfg.Missing)
+	}
+}
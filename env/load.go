@@ -0,0 +1,144 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+/*
+ Load configuration struct from environment
+
+ type Config struct {
+    Url     *url.URL          `env:"DB_URL" required:"true"`
+    Timeout time.Duration     `env:"DB_TIMEOUT" default:"5s"`
+    Hosts   []string          `env:"HOSTS" separator:";"`
+    Labels  map[string]string `env:"LABELS" default:"env:dev,team:core" kvseparator:":"`
+    Cache   CacheConfig       `prefix:"CACHE_"`
+ }
+
+ err := env.Load(&cfg)
+*/
+
+const (
+	tagEnv         = "env"
+	tagDefault     = "default"
+	tagRequired    = "required"
+	tagPrefix      = "prefix"
+	tagSeparator   = "separator"
+	tagKvSeparator = "kvseparator"
+
+	defaultSeparator   = ","
+	defaultKvSeparator = "="
+)
+
+// Load fills the struct pointed by v from environment variables described by field tags:
+//
+//	env         variable name, fields without it are skipped, unless they are structs
+//	default     value used when the variable is not set
+//	required    "true" reports the variable which is not set and has no default
+//	prefix      prefix of variable names of the nested struct
+//	separator   separator of slice items and map entries, "," by default
+//	kvseparator separator of a map entry key and value, "=" by default
+//
+// Every primitive type, time.Duration, url.URL, net.IPNet, slices, maps, pointers to them, *time.Location
+// and types implementing encoding.TextUnmarshaler, like ByteSize and LogLevel, are supported. Booleans
+// are parsed with ParseBool. All bad and missing variables are reported at once with Errors.
+func Load(v interface{}) error {
+	return load(v, "", readEnv)
+}
+
+func load(v interface{}, prefix string, read func(key string) (string, error)) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("env: Load expects a pointer to struct")
+	}
+	var errs Errors
+	loadStruct(rv.Elem(), prefix, read, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func loadStruct(v reflect.Value, prefix string, read func(key string) (string, error), errs *Errors) {
+	walkFields(v, prefix, true, func(key string, field reflect.StructField, fv reflect.Value) {
+		value, err := read(key)
+		if err != nil {
+			*errs = append(*errs, err.(*VarError))
+			return
+		}
+		if value == "" {
+			value = field.Tag.Get(tagDefault)
+		}
+		if value == "" {
+			if isRequired(field) {
+				*errs = append(*errs, &VarError{Key: key, Err: ErrRequired})
+			}
+			return
+		}
+		opts := fieldOptions{
+			separator:   field.Tag.Get(tagSeparator),
+			kvSeparator: field.Tag.Get(tagKvSeparator),
+		}
+		if opts.separator == "" {
+			opts.separator = defaultSeparator
+		}
+		if opts.kvSeparator == "" {
+			opts.kvSeparator = defaultKvSeparator
+		}
+		if err := parseInto(fv, value, opts); err != nil {
+			*errs = append(*errs, malformed(key, value, err, isSecret(field)))
+		}
+	})
+}
+
+// walkFields calls fn for every field with the env tag, nested structs are walked with their prefix.
+// Nil pointers to nested structs are allocated when alloc is set, otherwise a temporary zero struct
+// is walked, so read only walks never modify the struct.
+func walkFields(v reflect.Value, prefix string, alloc bool, fn func(key string, field reflect.StructField, fv reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+		fv := v.Field(i)
+		key, ok := field.Tag.Lookup(tagEnv)
+		if !ok {
+			if isNested(field.Type) {
+				if fv.Kind() == reflect.Ptr {
+					switch {
+					case !fv.IsNil():
+					case alloc && fv.CanSet():
+						fv.Set(reflect.New(field.Type.Elem()))
+					default:
+						fv = reflect.New(field.Type.Elem())
+					}
+					fv = fv.Elem()
+				}
+				walkFields(fv, prefix+field.Tag.Get(tagPrefix), alloc, fn)
+			}
+			continue
+		}
+		fn(prefix+key, field, fv)
+	}
+}
+
+func isRequired(field reflect.StructField) bool {
+	required, _ := strconv.ParseBool(field.Tag.Get(tagRequired))
+	return required
+}
+
+// isNested reports whether fields of the struct type are loaded as nested variables.
+func isNested(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case urlType, ipNetType, locationType.Elem():
+		return false
+	}
+	return t.Kind() == reflect.Struct && !reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
@@ -0,0 +1,140 @@
+package env
+
+import (
+	"encoding"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	urlType             = reflect.TypeOf(url.URL{})
+	ipNetType           = reflect.TypeOf(net.IPNet{})
+	locationType        = reflect.TypeOf((*time.Location)(nil))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// fieldOptions are the parsing options of a struct field.
+type fieldOptions struct {
+	separator   string
+	kvSeparator string
+}
+
+// parseInto parses the string into the value, which must be settable.
+func parseInto(v reflect.Value, s string, opts fieldOptions) error {
+	if v.Type() == locationType {
+		// locations are compared by pointer, e.g. time.Local, so keep the one LoadLocation returns
+		l, err := time.LoadLocation(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(l))
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return parseInto(v.Elem(), s, opts)
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch v.Type() {
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case urlType:
+		u, err := url.Parse(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(*u))
+		return nil
+	case ipNetType:
+		n, err := parseIPNet(s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(*n))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		parts := split(s, opts.separator)
+		list := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, p := range parts {
+			if err := parseInto(list.Index(i), p, opts); err != nil {
+				return err
+			}
+		}
+		v.Set(list)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for _, p := range split(s, opts.separator) {
+			kv := strings.SplitN(p, opts.kvSeparator, 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("expect key%svalue, has: %s", opts.kvSeparator, p)
+			}
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := parseInto(key, strings.TrimSpace(kv[0]), opts); err != nil {
+				return err
+			}
+			val := reflect.New(v.Type().Elem()).Elem()
+			if err := parseInto(val, strings.TrimSpace(kv[1]), opts); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, val)
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// split splits the list by the separator, trims the items and skips empty ones.
+func split(s, sep string) []string {
+	list := make([]string, 0)
+	for _, p := range strings.Split(s, sep) {
+		if p = strings.TrimSpace(p); p != "" {
+			list = append(list, p)
+		}
+	}
+	return list
+}
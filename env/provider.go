@@ -0,0 +1,140 @@
+package env
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/*
+ Layered configuration
+
+ p := env.NewProvider(
+    env.Flags(flag.CommandLine),
+    env.Environ(),
+    env.MustDotEnv(".env"),
+    env.MustJSONFile("config.json"),
+ )
+ env.SetProvider(p)
+ port := env.GetInt("PORT", 8080)  // reads through the provider
+ log.Print(p.Origin("PORT"))        // e.g. "flags"
+*/
+
+// Source is a single layer of configuration.
+type Source interface {
+	// Name describes the source in provenance reports.
+	Name() string
+	// Lookup returns the raw value of the variable.
+	Lookup(key string) (string, bool)
+	// Keys lists variables known to the source.
+	Keys() []string
+}
+
+// Provider merges sources, the value of the variable is taken from the first source which has it.
+type Provider struct {
+	mu      sync.RWMutex
+	sources []Source
+	reload  reloadState
+}
+
+// NewProvider returns the provider of sources in priority order, the first source wins.
+func NewProvider(sources ...Source) *Provider {
+	return &Provider{
+		sources: sources,
+	}
+}
+
+var (
+	providerMu      sync.RWMutex
+	defaultProvider = NewProvider(Environ())
+)
+
+// SetProvider replaces the provider used by package functions, the process environment by default.
+func SetProvider(p *Provider) {
+	providerMu.Lock()
+	defaultProvider = p
+	providerMu.Unlock()
+}
+
+// DefaultProvider returns the provider used by package functions.
+func DefaultProvider() *Provider {
+	providerMu.RLock()
+	defer providerMu.RUnlock()
+	return defaultProvider
+}
+
+// Lookup returns the value of the variable and the name of the source it came from. Empty values are
+// treated as not set, like by package getters, so lower priority sources are consulted.
+func (p *Provider) Lookup(key string) (value, source string, ok bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, s := range p.sources {
+		if v, ok := s.Lookup(key); ok && strings.TrimSpace(v) != "" {
+			return v, s.Name(), true
+		}
+	}
+	return "", "", false
+}
+
+// Origin returns the name of the source the variable comes from, or an empty string.
+func (p *Provider) Origin(key string) string {
+	_, source, _ := p.Lookup(key)
+	return source
+}
+
+// Provenance returns the source name of every variable known to the sources.
+func (p *Provider) Provenance() map[string]string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	origins := make(map[string]string)
+	for _, s := range p.sources {
+		for _, k := range s.Keys() {
+			if _, ok := origins[k]; !ok {
+				origins[k] = s.Name()
+			}
+		}
+	}
+	return origins
+}
+
+// Read returns the trimmed value of the variable. When the variable is not set, but the variable with
+// FileSuffix is, the value is read from the file it points to, like Docker and Kubernetes secrets are
+// mounted. The trailing newline of the file is trimmed.
+func (p *Provider) Read(key string) (string, error) {
+	return p.read(key)
+}
+
+func (p *Provider) read(key string) (string, error) {
+	if v, _, _ := p.Lookup(key); v != "" {
+		return strings.TrimSpace(v), nil
+	}
+	fileKey := key + FileSuffix
+	path, _, _ := p.Lookup(fileKey)
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", &VarError{Key: fileKey, Value: path, Err: err}
+	}
+	v := strings.TrimSuffix(string(data), "\n")
+	return strings.TrimSuffix(v, "\r"), nil
+}
+
+// Load fills the struct from the provider, see env.Load.
+func (p *Provider) Load(v interface{}) error {
+	return load(v, "", p.read)
+}
+
+// Keys returns sorted names of all variables known to the sources.
+func (p *Provider) Keys() []string {
+	origins := p.Provenance()
+	keys := make([]string, 0, len(origins))
+	for k := range origins {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
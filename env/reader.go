@@ -0,0 +1,176 @@
+package env
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+ Read configuration and fail startup listing all problems at once
+
+ r := env.NewReader()
+ cfg := Config{
+    Url:  r.Url("DB_URL", ""),
+    Port: r.PositiveInt("PORT", 8080),
+ }
+ if err := r.Validate(); err != nil {
+    log.Fatal(err)
+ }
+*/
+
+// Reader reads variables like Get functions, but records every malformed or missing variable,
+// so all problems are reported at once by Validate.
+type Reader struct {
+	prefix   string
+	fallback bool
+
+	mu   sync.Mutex
+	errs Errors
+	keys map[string]bool
+}
+
+// ReaderOption configures the Reader.
+type ReaderOption func(r *Reader)
+
+// WithFallback makes the prefixed reader fall back to the unprefixed variable when the prefixed
+// one is not set.
+func WithFallback() ReaderOption {
+	return func(r *Reader) {
+		r.fallback = true
+	}
+}
+
+func NewReader() *Reader {
+	return &Reader{
+		keys: make(map[string]bool),
+	}
+}
+
+// WithPrefix returns the reader scoped to the module, the prefix is prepended to every variable name:
+//
+//	billing := env.WithPrefix("BILLING_", env.WithFallback())
+//	port := billing.Int("PORT", 8080) // BILLING_PORT, or PORT when not set
+func WithPrefix(prefix string, opts ...ReaderOption) *Reader {
+	r := NewReader()
+	r.prefix = prefix
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Keys returns sorted names of all variables the reader has looked up, with the prefix.
+func (r *Reader) Keys() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := make([]string, 0, len(r.keys))
+	for k := range r.keys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Str returns the variable or the default when the variable is not set.
+func (r *Reader) Str(key, def string) string {
+	v, err := r.read(r.prefix + key)
+	r.record(err)
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+// Required returns the variable, the variable which is not set is recorded.
+func (r *Reader) Required(key string) string {
+	v, err := r.read(r.prefix + key)
+	if err == nil && v == "" {
+		err = &VarError{Key: r.prefix + key, Err: ErrRequired}
+	}
+	r.record(err)
+	return v
+}
+
+func (r *Reader) Url(key, def string) *url.URL {
+	u, err := lookupUrl(r.read, r.prefix+key, def)
+	r.record(err)
+	return u
+}
+
+func (r *Reader) Int(key string, def int) int {
+	return read(r, key, def, parseInt)
+}
+
+func (r *Reader) PositiveInt(key string, def int) int {
+	return read(r, key, def, parsePositiveInt)
+}
+
+func (r *Reader) Duration(key string, def time.Duration) time.Duration {
+	return read(r, key, def, parseDuration)
+}
+
+// Load fills the struct like env.Load, with the reader prefix, and records its errors.
+func (r *Reader) Load(v interface{}) {
+	r.record(load(v, r.prefix, r.read))
+}
+
+// Errors returns recorded problems.
+func (r *Reader) Errors() Errors {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append(Errors(nil), r.errs...)
+}
+
+// Validate returns Errors listing all recorded problems, or nil.
+func (r *Reader) Validate() error {
+	if errs := r.Errors(); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (r *Reader) record(err error) {
+	if err == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var list Errors
+	var ve *VarError
+	switch {
+	case errors.As(err, &list):
+		r.errs = append(r.errs, list...)
+	case errors.As(err, &ve):
+		r.errs = append(r.errs, ve)
+	default:
+		r.errs = append(r.errs, &VarError{Err: err})
+	}
+}
+
+// read returns the variable by its prefixed name, or the unprefixed one when the fallback is enabled,
+// and remembers looked up names.
+func (r *Reader) read(full string) (string, error) {
+	key := strings.TrimPrefix(full, r.prefix)
+	fallback := r.fallback && r.prefix != ""
+	r.mu.Lock()
+	r.keys[full] = true
+	if fallback {
+		r.keys[key] = true
+	}
+	r.mu.Unlock()
+	v, err := readEnv(full)
+	if err == nil && v == "" && fallback {
+		return readEnv(key)
+	}
+	return v, err
+}
+
+func read[T any](r *Reader, key string, def T, parse func(string) (T, error)) T {
+	v, err := lookupWith(r.read, r.prefix+key, def, parse)
+	r.record(err)
+	return v
+}
@@ -0,0 +1,235 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	assist "github.com/nooize/go-assist"
+)
+
+/*
+ Hot reload
+
+ p := env.NewProvider(env.Environ(), env.MustJSONFile("config.json"))
+ p.Validate(func(next *env.Provider) error {
+    var cfg Config
+    return next.Load(&cfg)
+ })
+ env.WatchDuration(p, "TIMEOUT", time.Second, func(old, new time.Duration) {
+    client.SetTimeout(new)
+ })
+ stop := p.ReloadOnSignal()
+ defer stop()
+*/
+
+// Reloadable is implemented by sources which can be re-read, like file sources.
+type Reloadable interface {
+	Source
+	// Reload returns a fresh snapshot of the source.
+	Reload() (Source, error)
+	// Path returns the file path of the source.
+	Path() string
+}
+
+// Validator checks the provider candidate before it replaces the current configuration.
+type Validator func(next *Provider) error
+
+type watcher struct {
+	key    string
+	last   string
+	notify func(old, new string)
+}
+
+type reloadState struct {
+	mu         sync.Mutex
+	validators []Validator
+	watchers   map[*watcher]bool
+	listeners  []func(err error)
+}
+
+// Validate registers the validator, the reload which fails any validator is rejected.
+func (p *Provider) Validate(v Validator) {
+	p.reload.mu.Lock()
+	p.reload.validators = append(p.reload.validators, v)
+	p.reload.mu.Unlock()
+}
+
+// OnReload registers the function called with the result of every reload triggered by a signal
+// or a file change.
+func (p *Provider) OnReload(fn func(err error)) {
+	p.reload.mu.Lock()
+	p.reload.listeners = append(p.reload.listeners, fn)
+	p.reload.mu.Unlock()
+}
+
+// Reload re-reads reloadable sources and validates the result. On success the sources are swapped
+// atomically and watchers of changed variables are notified, otherwise the last good configuration
+// is kept and the error is returned.
+func (p *Provider) Reload() error {
+	notify, err := p.swapSources()
+	// watchers are called unlocked, so they can read the provider or cancel themselves
+	for _, fn := range notify {
+		fn()
+	}
+	return err
+}
+
+// swapSources reloads and validates the sources, returns notifications of the changed watchers.
+func (p *Provider) swapSources() ([]func(), error) {
+	p.reload.mu.Lock()
+	defer p.reload.mu.Unlock()
+
+	p.mu.RLock()
+	next := make([]Source, len(p.sources))
+	copy(next, p.sources)
+	p.mu.RUnlock()
+	for i, s := range next {
+		if r, ok := s.(Reloadable); ok {
+			fresh, err := r.Reload()
+			if err != nil {
+				return nil, fmt.Errorf("env: reload %s : %w", s.Name(), err)
+			}
+			next[i] = fresh
+		}
+	}
+	candidate := NewProvider(next...)
+	for _, v := range p.reload.validators {
+		if err := v(candidate); err != nil {
+			return nil, fmt.Errorf("env: reload rejected : %w", err)
+		}
+	}
+
+	p.mu.Lock()
+	p.sources = next
+	p.mu.Unlock()
+
+	var notify []func()
+	for w := range p.reload.watchers {
+		v, _ := p.read(w.key)
+		if v != w.last {
+			fn, old := w.notify, w.last
+			w.last = v
+			notify = append(notify, func() { fn(old, v) })
+		}
+	}
+	return notify, nil
+}
+
+// watch registers the raw value watcher, the returned function cancels it.
+func (p *Provider) watch(key string, notify func(old, new string)) func() {
+	w := &watcher{key: key, notify: notify}
+	p.reload.mu.Lock()
+	defer p.reload.mu.Unlock()
+	w.last, _ = p.read(key)
+	if p.reload.watchers == nil {
+		p.reload.watchers = make(map[*watcher]bool)
+	}
+	p.reload.watchers[w] = true
+	return func() {
+		p.reload.mu.Lock()
+		delete(p.reload.watchers, w)
+		p.reload.mu.Unlock()
+	}
+}
+
+// Watch subscribes fn to changes of the variable made by reloads. Values are parsed like by Lookup
+// functions, a malformed value gives the default. Returns the function which cancels the subscription.
+func Watch[T any](p *Provider, key string, def T, parse func(string) (T, error), fn func(old, new T)) (cancel func()) {
+	value := func(raw string) T {
+		if raw == "" {
+			return def
+		}
+		v, err := parse(raw)
+		if err != nil {
+			return def
+		}
+		return v
+	}
+	return p.watch(key, func(old, new string) {
+		fn(value(old), value(new))
+	})
+}
+
+func WatchStr(p *Provider, key, def string, fn func(old, new string)) (cancel func()) {
+	return Watch(p, key, def, func(s string) (string, error) { return s, nil }, fn)
+}
+
+func WatchInt(p *Provider, key string, def int, fn func(old, new int)) (cancel func()) {
+	return Watch(p, key, def, parseInt, fn)
+}
+
+func WatchBool(p *Provider, key string, def bool, fn func(old, new bool)) (cancel func()) {
+	return Watch(p, key, def, ParseBool, fn)
+}
+
+func WatchDuration(p *Provider, key string, def time.Duration, fn func(old, new time.Duration)) (cancel func()) {
+	return Watch(p, key, def, parseDuration, fn)
+}
+
+// ReloadOnSignal reloads the provider on every signal, SIGHUP when none given. Returns the function
+// which stops listening. The signals are reserved with assist.ReserveSignals until then, so apx
+// applications and di services do not shut down on them.
+func (p *Provider) ReloadOnSignal(sig ...os.Signal) (stop func()) {
+	if len(sig) == 0 {
+		sig = []os.Signal{syscall.SIGHUP}
+	}
+	release := assist.ReserveSignals(sig...)
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+				p.triggered(p.Reload())
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			release()
+			close(done)
+		})
+	}
+}
+
+// WatchFiles checks files of reloadable sources every interval and reloads the provider when
+// any of them is changed, created or removed. A failed reload is tried again on the next check.
+// A non-positive interval means assist.DefaultWatchInterval. Returns the function which stops watching.
+func (p *Provider) WatchFiles(interval time.Duration) (stop func()) {
+	return assist.WatchFiles(interval, p.filePaths, func() error {
+		err := p.Reload()
+		p.triggered(err)
+		return err
+	})
+}
+
+// filePaths lists files of reloadable sources.
+func (p *Provider) filePaths() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	paths := make([]string, 0)
+	for _, s := range p.sources {
+		if r, ok := s.(Reloadable); ok {
+			paths = append(paths, r.Path())
+		}
+	}
+	return paths
+}
+
+func (p *Provider) triggered(err error) {
+	p.reload.mu.Lock()
+	listeners := p.reload.listeners
+	p.reload.mu.Unlock()
+	for _, fn := range listeners {
+		fn(err)
+	}
+}
@@ -0,0 +1,53 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	assist "github.com/nooize/go-assist"
+)
+
+func TestReloadOnSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"port": 8080}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	p := NewProvider(MustJSONFile(path))
+	reloaded := make(chan error, 1)
+	p.OnReload(func(err error) {
+		reloaded <- err
+	})
+
+	stop := p.ReloadOnSignal()
+	if !assist.IsSignalReserved(syscall.SIGHUP) {
+		t.Fatal("SIGHUP is not reserved for reloads")
+	}
+	if err := os.WriteFile(path, []byte(`{"port": 9090}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	self, _ := os.FindProcess(os.Getpid())
+	if err := self.Signal(syscall.SIGHUP); err != nil {
+		stop()
+		t.Skipf("fail to send SIGHUP : %v", err)
+	}
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("unexpected error : %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("provider is not reloaded on SIGHUP")
+	}
+	if port, _ := p.Read("PORT"); port != "9090" {
+		t.Fatalf("expected reloaded port, got %q", port)
+	}
+
+	stop()
+	stop()
+	if assist.IsSignalReserved(syscall.SIGHUP) {
+		t.Fatal("SIGHUP is reserved after stop")
+	}
+}
@@ -0,0 +1,53 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const redacted = "******"
+
+// Secret is a string which never shows its value in String, fmt output, JSON or text encoding,
+// so credentials don't leak into logs. Use Reveal to get the value.
+type Secret string
+
+// Reveal returns the secret value.
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return "env.Secret(\"" + redacted + "\")"
+}
+
+// Format implements the fmt.Formatter interface, the value is redacted for every verb.
+func (s Secret) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('#') {
+		fmt.Fprint(f, s.GoString())
+		return
+	}
+	fmt.Fprint(f, redacted)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+// GetSecret returns the variable, or the file content pointed by the variable with FileSuffix, as a Secret.
+func GetSecret(key, def string) Secret {
+	return Secret(GetStr(key, def))
+}
+
+func (r *Reader) Secret(key, def string) Secret {
+	return Secret(r.Str(key, def))
+}
@@ -0,0 +1,300 @@
+package env
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MapSource is a static source, or a snapshot of the file source which is re-read by Reload.
+type MapSource struct {
+	name   string
+	values map[string]string
+	path   string
+	open   func(path string) (*MapSource, error)
+}
+
+// NewMapSource returns the source of the values, named for provenance reports.
+func NewMapSource(name string, values map[string]string) *MapSource {
+	return &MapSource{name: name, values: values}
+}
+
+func (s *MapSource) Name() string {
+	return s.name
+}
+
+func (s *MapSource) Lookup(key string) (string, bool) {
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Path returns the path of the file source, or an empty string.
+func (s *MapSource) Path() string {
+	return s.path
+}
+
+// Reload re-reads the file source and returns the new snapshot, the source itself is not changed.
+// Static sources return themselves.
+func (s *MapSource) Reload() (Source, error) {
+	if s.open == nil {
+		return s, nil
+	}
+	return s.open(s.path)
+}
+
+func (s *MapSource) Keys() []string {
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type environ struct{}
+
+// Environ returns the process environment source.
+func Environ() Source {
+	return environ{}
+}
+
+func (environ) Name() string {
+	return "env"
+}
+
+func (environ) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (environ) Keys() []string {
+	list := os.Environ()
+	keys := make([]string, 0, len(list))
+	for _, kv := range list {
+		keys = append(keys, strings.SplitN(kv, "=", 2)[0])
+	}
+	return keys
+}
+
+type flags struct {
+	fs *flag.FlagSet
+}
+
+// Flags returns the source of command-line flags which were set explicitly. Flag names are turned into
+// variable names by KeyName, e.g. -db-url is DB_URL. The flag set must be parsed before lookups.
+func Flags(fs *flag.FlagSet) Source {
+	return flags{fs}
+}
+
+func (flags) Name() string {
+	return "flags"
+}
+
+func (s flags) Lookup(key string) (v string, ok bool) {
+	s.fs.Visit(func(f *flag.Flag) {
+		if KeyName(f.Name) == key {
+			v, ok = f.Value.String(), true
+		}
+	})
+	return
+}
+
+func (s flags) Keys() []string {
+	keys := make([]string, 0)
+	s.fs.Visit(func(f *flag.Flag) {
+		keys = append(keys, KeyName(f.Name))
+	})
+	return keys
+}
+
+// KeyName turns the flag or config file key into the variable name: upper case with '-' and '.'
+// replaced by '_'.
+func KeyName(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+// DotEnv returns the source of the file in dotenv syntax:
+//
+//	# comment
+//	export KEY=value # comment
+//	QUOTED="line\nnext ${KEY}"
+//	LITERAL='no ${expansion} here'
+//
+// ${VAR} and $VAR are expanded in unquoted and double-quoted values, from variables defined earlier
+// in the file or from the process environment.
+func DotEnv(path string) (*MapSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values, err := ParseDotEnv(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s : %w", path, err)
+	}
+	return &MapSource{name: path, values: values, path: path, open: DotEnv}, nil
+}
+
+// MustDotEnv is like DotEnv, a missing file gives an empty source, other errors panic.
+func MustDotEnv(path string) *MapSource {
+	return mustFile(path, DotEnv)
+}
+
+// OptionalDotEnv is like DotEnv, but a missing file gives an empty source, also on reload.
+func OptionalDotEnv(path string) (*MapSource, error) {
+	return optionalFile(path, DotEnv)
+}
+
+// ParseDotEnv parses the content in dotenv syntax, see DotEnv.
+func ParseDotEnv(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	expand := func(name string) string {
+		if v, ok := values[name]; ok {
+			return v
+		}
+		return os.Getenv(name)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		i := strings.Index(line, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d : expect KEY=value", n)
+		}
+		key := strings.TrimSpace(line[:i])
+		raw := strings.TrimSpace(line[i+1:])
+		var value string
+		switch {
+		case strings.HasPrefix(raw, "'"):
+			end := strings.Index(raw[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d : unterminated quote", n)
+			}
+			value = raw[1 : end+1]
+		case strings.HasPrefix(raw, "\""):
+			end := closingQuote(raw)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d : unterminated quote", n)
+			}
+			unquoted, err := strconv.Unquote(raw[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d : %w", n, err)
+			}
+			value = os.Expand(unquoted, expand)
+		default:
+			if c := strings.Index(raw, " #"); c >= 0 {
+				raw = strings.TrimSpace(raw[:c])
+			}
+			value = os.Expand(raw, expand)
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+// closingQuote returns the index of the double quote closing the string, escaped quotes are skipped.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// JSONFile returns the source of the JSON config file. Nested objects are flattened and keys are turned
+// into variable names by KeyName, e.g. {"db": {"url": "..."}} is DB_URL. Arrays are joined with commas.
+func JSONFile(path string) (*MapSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// numbers are kept as written, float64 would round big integers like IDs
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc map[string]interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%s : %w", path, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("%s : unexpected data after the top-level value", path)
+	}
+	values := make(map[string]string)
+	flatten(values, "", doc)
+	return &MapSource{name: path, values: values, path: path, open: JSONFile}, nil
+}
+
+// MustJSONFile is like JSONFile, a missing file gives an empty source, other errors panic.
+func MustJSONFile(path string) *MapSource {
+	return mustFile(path, JSONFile)
+}
+
+// OptionalJSONFile is like JSONFile, but a missing file gives an empty source, also on reload.
+func OptionalJSONFile(path string) (*MapSource, error) {
+	return optionalFile(path, JSONFile)
+}
+
+func flatten(values map[string]string, prefix string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			flatten(values, prefix+KeyName(k)+"_", item)
+		}
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = scalar(item)
+		}
+		values[strings.TrimSuffix(prefix, "_")] = strings.Join(items, defaultSeparator)
+	default:
+		values[strings.TrimSuffix(prefix, "_")] = scalar(v)
+	}
+}
+
+func scalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
+
+func mustFile(path string, open func(string) (*MapSource, error)) *MapSource {
+	s, err := optionalFile(path, open)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func optionalFile(path string, open func(string) (*MapSource, error)) (*MapSource, error) {
+	reopen := func(path string) (*MapSource, error) {
+		return optionalFile(path, open)
+	}
+	s, err := open(path)
+	if os.IsNotExist(err) {
+		return &MapSource{name: path, values: map[string]string{}, path: path, open: reopen}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.open = reopen
+	return s, nil
+}
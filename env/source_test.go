@@ -0,0 +1,106 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	t.Setenv("ENV_TEST_HOME", "/home/test")
+	tests := []struct {
+		name string
+		in   string
+		want map[string]string
+	}{
+		{"plain", "A=1\nB = two ", map[string]string{"A": "1", "B": "two"}},
+		{"comments and blank lines", "# comment\n\n  # indented\nA=1", map[string]string{"A": "1"}},
+		{"export", "export A=1\nexport B='2'", map[string]string{"A": "1", "B": "2"}},
+		{"empty", "A=\nB=''\nC=\"\"", map[string]string{"A": "", "B": "", "C": ""}},
+		{"inline comment", "A=value # comment\nB=a#b\nC=\"x # y\" # comment\nD='x # y' # comment",
+			map[string]string{"A": "value", "B": "a#b", "C": "x # y", "D": "x # y"}},
+		{"single quotes", `A='line\n ${ENV_TEST_HOME} "q"'`, map[string]string{"A": `line\n ${ENV_TEST_HOME} "q"`}},
+		{"double quotes", `A="line\nnext \"q\" \t 'x'"`, map[string]string{"A": "line\nnext \"q\" \t 'x'"}},
+		{"equals in value", "A=a=b\nB=\"c=d\"", map[string]string{"A": "a=b", "B": "c=d"}},
+		{"expansion", "A=1\nB=${A}2\nC=\"$B 3\"\nD='$A'", map[string]string{"A": "1", "B": "12", "C": "12 3", "D": "$A"}},
+		{"expansion order", "B=${A}x\nA=1\nC=$A", map[string]string{"A": "1", "B": "x", "C": "1"}},
+		{"redefined", "A=1\nB=$A\nA=2\nC=$A", map[string]string{"A": "2", "B": "1", "C": "2"}},
+		{"process environment", "A=$ENV_TEST_HOME/bin\nENV_TEST_HOME=/opt\nB=${ENV_TEST_HOME}/bin",
+			map[string]string{"A": "/home/test/bin", "ENV_TEST_HOME": "/opt", "B": "/opt/bin"}},
+		{"missing variable", "A=${ENV_TEST_MISSING}x", map[string]string{"A": "x"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseDotEnv([]byte(tt.in))
+		if err != nil {
+			t.Errorf("%s : unexpected error : %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s : got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseDotEnvErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		line string
+	}{
+		{"A=1\nNOVALUE", "line 2 "},
+		{"A=1\n\n=value", "line 3 "},
+		{"A='open", "line 1 "},
+		{"A=1\nB=\"open", "line 2 "},
+		{`A="bad \q escape"`, "line 1 "},
+	}
+	for _, tt := range tests {
+		_, err := ParseDotEnv([]byte(tt.in))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.line) {
+			t.Errorf("%q : got %v, want error at %s", tt.in, err, tt.line)
+		}
+	}
+}
+
+func TestJSONFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	data := `{
+		"id": 9007199254740993,
+		"ratio": 0.25,
+		"big": 1e3,
+		"debug": true,
+		"name": null,
+		"db": {"max-conns": 10, "hosts": ["a", "b"]},
+		"limits": [{"rate": 12345678901234567890}]
+	}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := JSONFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	want := map[string]string{
+		"ID":           "9007199254740993",
+		"RATIO":        "0.25",
+		"BIG":          "1e3",
+		"DEBUG":        "true",
+		"NAME":         "",
+		"DB_MAX_CONNS": "10",
+		"DB_HOSTS":     "a,b",
+		"LIMITS":       `{"rate":12345678901234567890}`,
+	}
+	if !reflect.DeepEqual(s.values, want) {
+		t.Fatalf("got %q, want %q", s.values, want)
+	}
+
+	for _, bad := range []string{`{"a": 1} {"b": 2}`, `{"a": `, `[1]`} {
+		if err := os.WriteFile(path, []byte(bad), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := JSONFile(path); err == nil {
+			t.Errorf("%q : expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package env
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ByteSize is a size in bytes, parsed from human readable values like 512MiB, 1.5GB or 1024.
+// Units with "i" and single letter units are powers of 1024, two letter units are powers of 1000.
+type ByteSize uint64
+
+const (
+	KiB ByteSize = 1 << (10 * (iota + 1))
+	MiB
+	GiB
+	TiB
+	PiB
+)
+
+var byteUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   float64(KiB),
+	"kib": float64(KiB),
+	"kb":  1e3,
+	"m":   float64(MiB),
+	"mib": float64(MiB),
+	"mb":  1e6,
+	"g":   float64(GiB),
+	"gib": float64(GiB),
+	"gb":  1e9,
+	"t":   float64(TiB),
+	"tib": float64(TiB),
+	"tb":  1e12,
+	"p":   float64(PiB),
+	"pib": float64(PiB),
+	"pb":  1e15,
+}
+
+// ParseByteSize parses human readable size, see ByteSize.
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsLetter(r)
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	unit, ok := byteUnits[strings.ToLower(s[i:])]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit %q", s[i:])
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(s[:i]), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("expect size like 512MiB, has: %s", s)
+	}
+	size := n * unit
+	// float64(math.MaxUint64) is rounded up to 1<<64 which does not fit either
+	if size >= math.MaxUint64 {
+		return 0, fmt.Errorf("size is too big: %s", s)
+	}
+	return ByteSize(size), nil
+}
+
+func (b ByteSize) String() string {
+	for _, u := range []struct {
+		name string
+		size ByteSize
+	}{{"PiB", PiB}, {"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB}} {
+		if b >= u.size && b%u.size == 0 {
+			return strconv.FormatUint(uint64(b/u.size), 10) + u.name
+		}
+	}
+	return strconv.FormatUint(uint64(b), 10) + "B"
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// LogLevel is a logging level, parsed case-insensitively from its name.
+type LogLevel int8
+
+const (
+	LevelTrace LogLevel = iota - 1
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+	LevelPanic
+)
+
+var levelNames = map[LogLevel]string{
+	LevelTrace: "trace",
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+	LevelFatal: "fatal",
+	LevelPanic: "panic",
+}
+
+// ParseLogLevel parses the level name, "warning" is accepted as well.
+func ParseLogLevel(s string) (LogLevel, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "warning" {
+		return LevelWarn, nil
+	}
+	for l, name := range levelNames {
+		if name == s {
+			return l, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", s)
+}
+
+func (l LogLevel) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("LogLevel(%d)", int8(l))
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (l *LogLevel) UnmarshalText(text []byte) error {
+	level, err := ParseLogLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// ParseBool accepts true, 1, yes, on, t, y and false, 0, no, off, f, n in any case.
+func ParseBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "1", "yes", "on", "t", "y":
+		return true, nil
+	case "false", "0", "no", "off", "f", "n":
+		return false, nil
+	}
+	return false, fmt.Errorf("expect boolean, has: %s", s)
+}
@@ -0,0 +1,111 @@
+package env
+
+import "testing"
+
+func TestParseBool(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+		err  bool
+	}{
+		{"true", true, false},
+		{"TRUE", true, false},
+		{" yes ", true, false},
+		{"1", true, false},
+		{"on", true, false},
+		{"t", true, false},
+		{"Y", true, false},
+		{"false", false, false},
+		{"0", false, false},
+		{"No", false, false},
+		{"off", false, false},
+		{"f", false, false},
+		{"n", false, false},
+		{"", false, true},
+		{"2", false, true},
+		{"enabled", false, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBool(tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseBool(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ByteSize
+		err  bool
+	}{
+		{"1024", 1024, false},
+		{"0", 0, false},
+		{"10b", 10, false},
+		{"1k", KiB, false},
+		{"1KiB", KiB, false},
+		{"1kb", 1000, false},
+		{"512MiB", 512 * MiB, false},
+		{"1.5GiB", 3 * GiB / 2, false},
+		{"2 GB", 2e9, false},
+		{"1T", TiB, false},
+		{"1pb", 1e15, false},
+		{"", 0, true},
+		{"-1MiB", 0, true},
+		{"1XB", 0, true},
+		{"MiB", 0, true},
+		{"1e30PiB", 0, true},
+		{"16384PiB", 0, true},
+		{"16383PiB", 16383 * PiB, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseByteSize(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestByteSizeString(t *testing.T) {
+	tests := []struct {
+		in   ByteSize
+		want string
+	}{
+		{0, "0B"},
+		{1000, "1000B"},
+		{KiB, "1KiB"},
+		{1536, "1536B"},
+		{512 * MiB, "512MiB"},
+		{2 * GiB, "2GiB"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("ByteSize(%d).String() = %q, want %q", uint64(tt.in), got, tt.want)
+		}
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want LogLevel
+		err  bool
+	}{
+		{"trace", LevelTrace, false},
+		{"DEBUG", LevelDebug, false},
+		{" info ", LevelInfo, false},
+		{"warn", LevelWarn, false},
+		{"Warning", LevelWarn, false},
+		{"error", LevelError, false},
+		{"fatal", LevelFatal, false},
+		{"panic", LevelPanic, false},
+		{"", LevelInfo, true},
+		{"verbose", LevelInfo, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLogLevel(tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseLogLevel(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
@@ -16,9 +16,11 @@ type MultiError struct {
 
 // NewMultiError: returns a thread safe instance of multierror
 func NewMultiError(err error) *MultiError {
-	return &MultiError{
+	m := &MultiError{
 		mutex: &sync.Mutex{},
 	}
+	m.Append(err)
+	return m
 }
 
 // Push adds an error to MultiError.
@@ -28,6 +30,23 @@ func (m *MultiError) Push(str string) {
 	m.errs = append(m.errs, errors.New(str))
 }
 
+// Append adds an error to MultiError, nil errors are ignored.
+func (m *MultiError) Append(err error) {
+	if err == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.errs = append(m.errs, err)
+}
+
+// Errors returns a copy of collected errors.
+func (m *MultiError) Errors() []error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]error(nil), m.errs...)
+}
+
 // HasError checks if MultiError has any error.
 func (m *MultiError) HasError() error {
 	if len(m.errs) == 0 {
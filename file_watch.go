@@ -0,0 +1,68 @@
+package assist
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+/*
+ Poll files for changes
+
+ stop := assist.WatchFiles(time.Minute, func() []string {
+    return []string{"config.json"}
+ }, reloadConfig)
+ defer stop()
+*/
+
+// DefaultWatchInterval is the check interval of WatchFiles called with a non-positive interval.
+const DefaultWatchInterval = 10 * time.Second
+
+// FileStamps describes modification time and size of the files. Missing files are described too,
+// so creating or removing a file changes the result. Empty paths are skipped.
+func FileStamps(paths ...string) string {
+	stamps := ""
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if fi, err := os.Stat(path); err == nil {
+			stamps += fmt.Sprintf("%s:%d:%d;", path, fi.ModTime().UnixNano(), fi.Size())
+		} else {
+			stamps += path + ":-;"
+		}
+	}
+	return stamps
+}
+
+// WatchFiles checks the files every interval and calls onChange when any of them is changed, created
+// or removed. When onChange fails, e.g. on a file caught half written, it is called again on the next
+// check. paths is called on every check. Returns the function which stops watching.
+func WatchFiles(interval time.Duration, paths func() []string, onChange func() error) (stop func()) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	stamps := FileStamps(paths()...)
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if now := FileStamps(paths()...); now != stamps && onChange() == nil {
+					stamps = now
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
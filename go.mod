@@ -1,10 +1,12 @@
 module github.com/nooize/go-assist
 
-go 1.17
+go 1.18
 
 require (
 	github.com/fatih/structs v1.1.0
 	golang.org/x/crypto v0.8.0
+	golang.org/x/text v0.9.0
+	software.sslmate.com/src/go-pkcs12 v0.2.0
 )
 
 require golang.org/x/sys v0.7.0 // indirect
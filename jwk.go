@@ -0,0 +1,280 @@
+package assist
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+/*
+ JSON Web Key (RFC 7517)
+
+ jwk, _ := assist.NewJWK(key)
+ jwk.Kid, _ = jwk.Thumbprint()
+ data, _ := json.Marshal(jwk.Public())
+*/
+
+var ErrInvalidJWK = errors.New("jwk: invalid key")
+
+// JWK is a JSON Web Key of "RSA", "EC", "OKP" (Ed25519) or "oct" (symmetric) type.
+// Binary values are base64url encoded without padding.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	// N and E are the RSA modulus and exponent.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// X and Y are EC coordinates, X is the Ed25519 public key.
+	X string `json:"x,omitempty"`
+	Y string `json:"y,omitempty"`
+	// D is the private exponent, the EC private key or the Ed25519 seed.
+	D  string `json:"d,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	Dp string `json:"dp,omitempty"`
+	Dq string `json:"dq,omitempty"`
+	Qi string `json:"qi,omitempty"`
+	// K is the symmetric key.
+	K string `json:"k,omitempty"`
+}
+
+// NewJWK converts RSA, ECDSA and Ed25519 public or private keys and []byte symmetric keys to JWK.
+func NewJWK(key interface{}) (*JWK, error) {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return &JWK{
+			Kty: "RSA",
+			N:   b64(key.N.Bytes()),
+			E:   b64(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case *rsa.PrivateKey:
+		j, _ := NewJWK(&key.PublicKey)
+		j.D = b64(key.D.Bytes())
+		if len(key.Primes) == 2 {
+			// CRT values are computed here, the key may be shared and must not be modified
+			p, q, one := key.Primes[0], key.Primes[1], big.NewInt(1)
+			j.P = b64(p.Bytes())
+			j.Q = b64(q.Bytes())
+			j.Dp = b64(new(big.Int).Mod(key.D, new(big.Int).Sub(p, one)).Bytes())
+			j.Dq = b64(new(big.Int).Mod(key.D, new(big.Int).Sub(q, one)).Bytes())
+			j.Qi = b64(new(big.Int).ModInverse(q, p).Bytes())
+		}
+		return j, nil
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		crv, err := curveName(key.Curve)
+		if err != nil {
+			return nil, err
+		}
+		return &JWK{
+			Kty: "EC",
+			Crv: crv,
+			X:   b64(key.X.FillBytes(make([]byte, size))),
+			Y:   b64(key.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case *ecdsa.PrivateKey:
+		j, err := NewJWK(&key.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		j.D = b64(key.D.FillBytes(make([]byte, (key.Curve.Params().BitSize+7)/8)))
+		return j, nil
+	case ed25519.PublicKey:
+		return &JWK{Kty: "OKP", Crv: "Ed25519", X: b64(key)}, nil
+	case ed25519.PrivateKey:
+		j, _ := NewJWK(key.Public())
+		j.D = b64(key.Seed())
+		return j, nil
+	case *ed25519.PrivateKey:
+		return NewJWK(*key)
+	case []byte:
+		return &JWK{Kty: "oct", K: b64(key)}, nil
+	}
+	return nil, ErrUnsupportedKey
+}
+
+// MarshalJWK encodes the key to JSON Web Key.
+func MarshalJWK(key interface{}) ([]byte, error) {
+	j, err := NewJWK(key)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(j)
+}
+
+// ParseJWK decodes JSON Web Key and returns its key, see JWK.Key.
+func ParseJWK(data []byte) (interface{}, error) {
+	var j JWK
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	return j.Key()
+}
+
+// IsPrivate reports whether the key contains private or symmetric material.
+func (j *JWK) IsPrivate() bool {
+	return j.D != "" || j.K != ""
+}
+
+// Public returns the copy of the key without private material.
+func (j *JWK) Public() *JWK {
+	return &JWK{Kty: j.Kty, Kid: j.Kid, Use: j.Use, Alg: j.Alg, Crv: j.Crv, N: j.N, E: j.E, X: j.X, Y: j.Y}
+}
+
+// Key returns *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey or []byte when the key has private
+// material and *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey otherwise.
+func (j *JWK) Key() (interface{}, error) {
+	switch j.Kty {
+	case "RSA":
+		n, e := jwkInt(j.N), jwkInt(j.E)
+		if n == nil || e == nil || !e.IsInt64() {
+			return nil, fmt.Errorf("%w : bad RSA modulus or exponent", ErrInvalidJWK)
+		}
+		pub := rsa.PublicKey{N: n, E: int(e.Int64())}
+		if j.D == "" {
+			return &pub, nil
+		}
+		key := rsa.PrivateKey{PublicKey: pub, D: jwkInt(j.D)}
+		if p, q := jwkInt(j.P), jwkInt(j.Q); p != nil && q != nil {
+			key.Primes = []*big.Int{p, q}
+		}
+		if key.D == nil || key.Primes == nil {
+			return nil, fmt.Errorf("%w : RSA private key without primes", ErrInvalidJWK)
+		}
+		if err := key.Validate(); err != nil {
+			return nil, fmt.Errorf("%w : %s", ErrInvalidJWK, err.Error())
+		}
+		key.Precompute()
+		return &key, nil
+	case "EC":
+		curve, err := curveByName(j.Crv)
+		if err != nil {
+			return nil, err
+		}
+		x, y := jwkInt(j.X), jwkInt(j.Y)
+		if x == nil || y == nil || !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("%w : point is not on curve %s", ErrInvalidJWK, j.Crv)
+		}
+		pub := ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		if j.D == "" {
+			return &pub, nil
+		}
+		d := jwkInt(j.D)
+		if d == nil || d.Sign() <= 0 || d.Cmp(curve.Params().N) >= 0 {
+			return nil, fmt.Errorf("%w : bad EC private key", ErrInvalidJWK)
+		}
+		if px, py := curve.ScalarBaseMult(d.Bytes()); px.Cmp(x) != 0 || py.Cmp(y) != 0 {
+			return nil, fmt.Errorf("%w : EC private key does not match the public key", ErrInvalidJWK)
+		}
+		return &ecdsa.PrivateKey{PublicKey: pub, D: d}, nil
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("%w : unsupported curve %q", ErrInvalidJWK, j.Crv)
+		}
+		if j.D != "" {
+			seed, err := base64.RawURLEncoding.DecodeString(j.D)
+			if err != nil || len(seed) != ed25519.SeedSize {
+				return nil, fmt.Errorf("%w : bad Ed25519 seed", ErrInvalidJWK)
+			}
+			key := ed25519.NewKeyFromSeed(seed)
+			if j.X != "" {
+				x, err := base64.RawURLEncoding.DecodeString(j.X)
+				if err != nil || !bytes.Equal(x, key.Public().(ed25519.PublicKey)) {
+					return nil, fmt.Errorf("%w : Ed25519 private key does not match the public key", ErrInvalidJWK)
+				}
+			}
+			return key, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w : bad Ed25519 public key", ErrInvalidJWK)
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		k, err := base64.RawURLEncoding.DecodeString(j.K)
+		if err != nil || len(k) == 0 {
+			return nil, fmt.Errorf("%w : bad symmetric key", ErrInvalidJWK)
+		}
+		return k, nil
+	}
+	return nil, fmt.Errorf("%w : unsupported key type %q", ErrInvalidJWK, j.Kty)
+}
+
+// PublicKey returns the public key, see JWK.Key.
+func (j *JWK) PublicKey() (crypto.PublicKey, error) {
+	key, err := j.Public().Key()
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Thumbprint returns the base64url encoded SHA-256 JWK thumbprint (RFC 7638), suitable as the key ID.
+func (j *JWK) Thumbprint() (string, error) {
+	var members string
+	switch j.Kty {
+	case "RSA":
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, j.E, j.N)
+	case "EC":
+		members = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, j.Crv, j.X, j.Y)
+	case "OKP":
+		members = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, j.Crv, j.X)
+	case "oct":
+		members = fmt.Sprintf(`{"k":%q,"kty":"oct"}`, j.K)
+	default:
+		return "", fmt.Errorf("%w : unsupported key type %q", ErrInvalidJWK, j.Kty)
+	}
+	sum := sha256.Sum256([]byte(members))
+	return b64(sum[:]), nil
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func jwkInt(s string) *big.Int {
+	if s == "" {
+		return nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil
+	}
+	return new(big.Int).SetBytes(b)
+}
+
+func curveName(c elliptic.Curve) (string, error) {
+	switch c {
+	case elliptic.P256():
+		return "P-256", nil
+	case elliptic.P384():
+		return "P-384", nil
+	case elliptic.P521():
+		return "P-521", nil
+	}
+	return "", ErrUnsupportedKey
+}
+
+func curveByName(name string) (elliptic.Curve, error) {
+	switch name {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "P-521":
+		return elliptic.P521(), nil
+	}
+	return nil, fmt.Errorf("%w : unsupported curve %q", ErrInvalidJWK, name)
+}
@@ -0,0 +1,158 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"math/big"
+
+	assist "github.com/nooize/go-assist"
+)
+
+// Algorithm is the JWS "alg" header value.
+type Algorithm string
+
+const (
+	HS256 Algorithm = "HS256"
+	HS384 Algorithm = "HS384"
+	HS512 Algorithm = "HS512"
+	RS256 Algorithm = "RS256"
+	ES256 Algorithm = "ES256"
+	EdDSA Algorithm = "EdDSA"
+)
+
+// Algorithms lists all supported algorithms.
+var Algorithms = []Algorithm{HS256, HS384, HS512, RS256, ES256, EdDSA}
+
+func (a Algorithm) hash() crypto.Hash {
+	switch a {
+	case HS384:
+		return crypto.SHA384
+	case HS512:
+		return crypto.SHA512
+	case EdDSA:
+		return 0
+	}
+	return crypto.SHA256
+}
+
+func (a Algorithm) digest(data []byte) []byte {
+	switch a.hash() {
+	case crypto.SHA384:
+		sum := sha512.Sum384(data)
+		return sum[:]
+	case crypto.SHA512:
+		sum := sha512.Sum512(data)
+		return sum[:]
+	}
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// signingKey checks the private key matches the algorithm.
+func (a Algorithm) signingKey(key interface{}) (interface{}, error) {
+	switch a {
+	case HS256, HS384, HS512:
+		if k, ok := key.([]byte); ok && len(k) > 0 {
+			return k, nil
+		}
+	case RS256:
+		if k, ok := key.(*rsa.PrivateKey); ok {
+			return k, nil
+		}
+	case ES256:
+		if k, ok := key.(*ecdsa.PrivateKey); ok && k.Curve == elliptic.P256() {
+			return k, nil
+		}
+	case EdDSA:
+		switch k := key.(type) {
+		case ed25519.PrivateKey:
+			return k, nil
+		case *ed25519.PrivateKey:
+			return *k, nil
+		}
+	default:
+		return nil, ErrAlgorithm
+	}
+	return nil, ErrKeyType
+}
+
+// verifyingKey checks the key matches the algorithm, public keys are derived from private ones.
+func (a Algorithm) verifyingKey(key interface{}) (interface{}, error) {
+	switch a {
+	case HS256, HS384, HS512:
+		return a.signingKey(key)
+	case RS256, ES256, EdDSA:
+		pub, err := assist.PublicKey(key)
+		if err != nil {
+			return nil, ErrKeyType
+		}
+		switch k := pub.(type) {
+		case *rsa.PublicKey:
+			if a == RS256 {
+				return k, nil
+			}
+		case *ecdsa.PublicKey:
+			if a == ES256 && k.Curve == elliptic.P256() {
+				return k, nil
+			}
+		case ed25519.PublicKey:
+			if a == EdDSA {
+				return k, nil
+			}
+		}
+		return nil, ErrKeyType
+	}
+	return nil, ErrAlgorithm
+}
+
+func (a Algorithm) sign(key interface{}, data []byte) ([]byte, error) {
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(a.hash().New, k)
+		mac.Write(data)
+		return mac.Sum(nil), nil
+	case *rsa.PrivateKey:
+		return rsa.SignPKCS1v15(rand.Reader, k, a.hash(), a.digest(data))
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, a.digest(data))
+		if err != nil {
+			return nil, err
+		}
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, data), nil
+	}
+	return nil, ErrKeyType
+}
+
+func (a Algorithm) verify(key interface{}, data, sig []byte) error {
+	ok := false
+	switch k := key.(type) {
+	case []byte:
+		expected, _ := a.sign(k, data)
+		ok = hmac.Equal(sig, expected)
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(k, a.hash(), a.digest(data), sig) == nil
+	case *ecdsa.PublicKey:
+		if len(sig) == 64 {
+			r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+			ok = ecdsa.Verify(k, a.digest(data), r, s)
+		}
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(k, data, sig)
+	}
+	if !ok {
+		return ErrSignature
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// NumericDate is the number of seconds since the epoch, zero means the claim is absent.
+type NumericDate int64
+
+// At returns the date of the time.
+func At(t time.Time) NumericDate {
+	return NumericDate(t.Unix())
+}
+
+func (d NumericDate) Time() time.Time {
+	return time.Unix(int64(d), 0)
+}
+
+// UnmarshalJSON accepts fractional seconds too.
+func (d *NumericDate) UnmarshalJSON(data []byte) error {
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("jwt: bad numeric date : %s", err.Error())
+	}
+	*d = NumericDate(math.Floor(f))
+	return nil
+}
+
+// Audience is the "aud" claim, a single string or an array of strings.
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*a = Audience{s}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("jwt: bad audience : %s", err.Error())
+	}
+	*a = list
+	return nil
+}
+
+// Contains reports whether the audience includes the value.
+func (a Audience) Contains(v string) bool {
+	for _, s := range a {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Claims are registered claims of the token, embed it to the struct with private claims:
+//
+//	type UserClaims struct {
+//		jwt.Claims
+//		Role string `json:"role"`
+//	}
+type Claims struct {
+	Issuer    string      `json:"iss,omitempty"`
+	Subject   string      `json:"sub,omitempty"`
+	Audience  Audience    `json:"aud,omitempty"`
+	ExpiresAt NumericDate `json:"exp,omitempty"`
+	NotBefore NumericDate `json:"nbf,omitempty"`
+	IssuedAt  NumericDate `json:"iat,omitempty"`
+	ID        string      `json:"jti,omitempty"`
+}
+
+// NewClaims returns claims issued now and expiring after ttl.
+func NewClaims(issuer, subject string, ttl time.Duration) Claims {
+	now := time.Now()
+	return Claims{
+		Issuer:    issuer,
+		Subject:   subject,
+		IssuedAt:  At(now),
+		ExpiresAt: At(now.Add(ttl)),
+	}
+}
+
+// validate checks time based claims, skew is the allowed clock difference with the issuer.
+func (c *Claims) validate(now time.Time, skew time.Duration, requireExp bool) error {
+	if c.ExpiresAt == 0 {
+		if requireExp {
+			return fmt.Errorf("%w : exp", ErrMissingClaim)
+		}
+	} else if !now.Before(c.ExpiresAt.Time().Add(skew)) {
+		return fmt.Errorf("%w : expired at %s", ErrExpired, c.ExpiresAt.Time().Format(time.RFC3339))
+	}
+	if c.NotBefore != 0 && now.Add(skew).Before(c.NotBefore.Time()) {
+		return fmt.Errorf("%w : valid from %s", ErrNotYetValid, c.NotBefore.Time().Format(time.RFC3339))
+	}
+	if c.IssuedAt != 0 && now.Add(skew).Before(c.IssuedAt.Time()) {
+		return fmt.Errorf("%w : issued at %s", ErrIssuedInFuture, c.IssuedAt.Time().Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package jwt
+
+import "errors"
+
+var (
+	ErrMalformed      = errors.New("jwt: malformed token")
+	ErrAlgorithm      = errors.New("jwt: unsupported or not allowed algorithm")
+	ErrKeyType        = errors.New("jwt: key does not match algorithm")
+	ErrKeyNotFound    = errors.New("jwt: key not found")
+	ErrSignature      = errors.New("jwt: signature is invalid")
+	ErrExpired        = errors.New("jwt: token is expired")
+	ErrNotYetValid    = errors.New("jwt: token is not valid yet")
+	ErrIssuedInFuture = errors.New("jwt: token is issued in the future")
+	ErrMissingClaim   = errors.New("jwt: required claim is missing")
+	ErrAudience       = errors.New("jwt: token audience is not accepted")
+	ErrIssuer         = errors.New("jwt: token issuer is not accepted")
+	ErrNoToken        = errors.New("jwt: no bearer token in request")
+)
@@ -0,0 +1,209 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	assist "github.com/nooize/go-assist"
+)
+
+/*
+ Sign and verify JSON Web Tokens
+
+ key, _ := assist.ParseX509PrivateKey(der)
+ signer, _ := jwt.NewSigner(jwt.ES256, key, "2024-01")
+ token, _ := signer.Sign(jwt.NewClaims("auth", "user-1", time.Hour))
+
+ v := jwt.NewVerifier(jwt.NewRemoteJWKS("https://auth/.well-known/jwks.json"))
+ v.Issuer = "auth"
+ var claims UserClaims
+ if _, err := v.Verify(token, &claims); err != nil {
+    ...
+ }
+*/
+
+// DefaultSkew is the default allowed clock difference with the token issuer.
+const DefaultSkew = time.Minute
+
+// Header is the JOSE header of the token.
+type Header struct {
+	Alg Algorithm `json:"alg"`
+	Typ string    `json:"typ,omitempty"`
+	Kid string    `json:"kid,omitempty"`
+}
+
+// Signer signs tokens with the key, the key ID is put to the "kid" header when not empty.
+type Signer struct {
+	Alg Algorithm
+	Kid string
+	key interface{}
+}
+
+// NewSigner checks the key matches the algorithm: []byte for HS*, *rsa.PrivateKey for RS256,
+// P-256 *ecdsa.PrivateKey for ES256 and ed25519.PrivateKey for EdDSA.
+func NewSigner(alg Algorithm, key interface{}, kid string) (*Signer, error) {
+	k, err := alg.signingKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{Alg: alg, Kid: kid, key: k}, nil
+}
+
+// Sign encodes the claims, usually a Claims or a struct embedding it, and signs the token.
+func (s *Signer) Sign(claims interface{}) (string, error) {
+	header, err := json.Marshal(Header{Alg: s.Alg, Typ: "JWT", Kid: s.Kid})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	data := encode(header) + "." + encode(payload)
+	sig, err := s.Alg.sign(s.key, []byte(data))
+	if err != nil {
+		return "", err
+	}
+	return data + "." + encode(sig), nil
+}
+
+// JWK returns the public key of the signer for the JWKS document, HMAC secrets are not published.
+func (s *Signer) JWK() (*assist.JWK, error) {
+	if _, ok := s.key.([]byte); ok {
+		return nil, fmt.Errorf("%w : symmetric key can not be published", ErrKeyType)
+	}
+	pub, err := assist.PublicKey(s.key)
+	if err != nil {
+		return nil, err
+	}
+	j, err := assist.NewJWK(pub)
+	if err != nil {
+		return nil, err
+	}
+	j.Kid, j.Alg, j.Use = s.Kid, string(s.Alg), "sig"
+	return j, nil
+}
+
+// Verifier checks the token signature and claims.
+type Verifier struct {
+	Keys KeySet
+	// Algorithms accepted, all supported algorithms when empty.
+	Algorithms []Algorithm
+	// Issuer is the accepted "iss", not checked when empty.
+	Issuer string
+	// Audience must be in "aud", not checked when empty.
+	Audience string
+	// Skew is the allowed clock difference with the issuer for exp, nbf and iat.
+	Skew time.Duration
+	// RequireExp rejects tokens without exp.
+	RequireExp bool
+	// Now returns the current time, time.Now when nil.
+	Now func() time.Time
+}
+
+func NewVerifier(keys KeySet) *Verifier {
+	return &Verifier{
+		Keys:       keys,
+		Skew:       DefaultSkew,
+		RequireExp: true,
+	}
+}
+
+// Verify checks the signature and registered claims of the token and decodes the payload to claims,
+// which may be nil. Returns the registered claims of the token.
+func (v *Verifier) Verify(token string, claims interface{}) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+	var header Header
+	if err := decodeJSON(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if !v.allowed(header.Alg) {
+		return nil, fmt.Errorf("%w : %q", ErrAlgorithm, header.Alg)
+	}
+	key, err := v.Keys.Key(header.Kid, header.Alg)
+	if err != nil {
+		return nil, err
+	}
+	if key, err = header.Alg.verifyingKey(key); err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if err = header.Alg.verify(key, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+
+	var registered Claims
+	if err = decodeJSON(parts[1], &registered); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+	if err = registered.validate(now, v.Skew, v.RequireExp); err != nil {
+		return nil, err
+	}
+	if v.Issuer != "" && registered.Issuer != v.Issuer {
+		return nil, fmt.Errorf("%w : %q", ErrIssuer, registered.Issuer)
+	}
+	if v.Audience != "" && !registered.Audience.Contains(v.Audience) {
+		return nil, fmt.Errorf("%w : %v", ErrAudience, []string(registered.Audience))
+	}
+	if claims != nil {
+		if err = decodeJSON(parts[1], claims); err != nil {
+			return nil, err
+		}
+	}
+	return &registered, nil
+}
+
+func (v *Verifier) allowed(alg Algorithm) bool {
+	list := v.Algorithms
+	if len(list) == 0 {
+		list = Algorithms
+	}
+	for _, a := range list {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
+
+// FromRequest returns the bearer token of the Authorization header.
+func FromRequest(r *http.Request) (string, error) {
+	auth := r.Header.Get("Authorization")
+	if len(auth) < 7 || !strings.EqualFold(auth[:7], "bearer ") {
+		return "", ErrNoToken
+	}
+	token := strings.TrimSpace(auth[7:])
+	if token == "" {
+		return "", ErrNoToken
+	}
+	return token, nil
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeJSON(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return ErrMalformed
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w : %s", ErrMalformed, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testKeys struct {
+	secret []byte
+	rsa    *rsa.PrivateKey
+	ec     *ecdsa.PrivateKey
+	ed     ed25519.PrivateKey
+}
+
+var keys = func() testKeys {
+	r, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	ec, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	_, ed, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return testKeys{secret: []byte("0123456789abcdef0123456789abcdef"), rsa: r, ec: ec, ed: ed}
+}()
+
+func (k testKeys) signing(alg Algorithm) interface{} {
+	switch alg {
+	case RS256:
+		return k.rsa
+	case ES256:
+		return k.ec
+	case EdDSA:
+		return k.ed
+	}
+	return k.secret
+}
+
+func (k testKeys) verifying(alg Algorithm) interface{} {
+	switch alg {
+	case RS256:
+		return &k.rsa.PublicKey
+	case ES256:
+		return &k.ec.PublicKey
+	case EdDSA:
+		return k.ed.Public()
+	}
+	return k.secret
+}
+
+type userClaims struct {
+	Claims
+	Role string `json:"role"`
+}
+
+func sign(t *testing.T, alg Algorithm, claims interface{}) string {
+	t.Helper()
+	s, err := NewSigner(alg, keys.signing(alg), "k1")
+	if err != nil {
+		t.Fatalf("%s : fail to create signer : %v", alg, err)
+	}
+	token, err := s.Sign(claims)
+	if err != nil {
+		t.Fatalf("%s : fail to sign : %v", alg, err)
+	}
+	return token
+}
+
+func TestSignVerify(t *testing.T) {
+	for _, alg := range Algorithms {
+		token := sign(t, alg, userClaims{Claims: NewClaims("auth", "user-1", time.Hour), Role: "admin"})
+		var claims userClaims
+		registered, err := NewVerifier(StaticKey(keys.verifying(alg))).Verify(token, &claims)
+		if err != nil {
+			t.Fatalf("%s : unexpected error : %v", alg, err)
+		}
+		if registered.Subject != "user-1" || claims.Subject != "user-1" || claims.Role != "admin" {
+			t.Fatalf("%s : unexpected claims %+v", alg, claims)
+		}
+		if alg != EdDSA && !strings.HasPrefix(string(alg), "HS") {
+			// private keys are accepted for verification too
+			if _, err = NewVerifier(StaticKey(keys.signing(alg))).Verify(token, nil); err != nil {
+				t.Fatalf("%s : private key is not accepted : %v", alg, err)
+			}
+		}
+	}
+}
+
+func TestVerifyTamperedToken(t *testing.T) {
+	for _, alg := range Algorithms {
+		token := sign(t, alg, NewClaims("auth", "user-1", time.Hour))
+		parts := strings.Split(token, ".")
+		forged, _ := json.Marshal(NewClaims("auth", "admin", time.Hour))
+		parts[1] = encode(forged)
+		if _, err := NewVerifier(StaticKey(keys.verifying(alg))).Verify(strings.Join(parts, "."), nil); !errors.Is(err, ErrSignature) {
+			t.Fatalf("%s : expected ErrSignature, got %v", alg, err)
+		}
+	}
+}
+
+func TestVerifyRejectsAlgorithms(t *testing.T) {
+	claims, _ := json.Marshal(NewClaims("auth", "user-1", time.Hour))
+	none, _ := json.Marshal(Header{Alg: "none", Typ: "JWT"})
+	unsigned := encode(none) + "." + encode(claims) + "."
+	if _, err := NewVerifier(StaticKey(keys.secret)).Verify(unsigned, nil); !errors.Is(err, ErrAlgorithm) {
+		t.Fatalf("alg none : expected ErrAlgorithm, got %v", err)
+	}
+
+	// HS256 token signed with the public key bytes must not be verified by the public key
+	for _, pub := range []interface{}{&keys.rsa.PublicKey, &keys.ec.PublicKey, keys.ed.Public()} {
+		hs, _ := json.Marshal(Header{Alg: HS256, Typ: "JWT"})
+		data := encode(hs) + "." + encode(claims)
+		sig, _ := HS256.sign([]byte("public key"), []byte(data))
+		if _, err := NewVerifier(StaticKey(pub)).Verify(data+"."+encode(sig), nil); !errors.Is(err, ErrKeyType) {
+			t.Fatalf("HS256 with %T : expected ErrKeyType, got %v", pub, err)
+		}
+	}
+
+	token := sign(t, HS256, NewClaims("auth", "user-1", time.Hour))
+	v := NewVerifier(StaticKey(keys.secret))
+	v.Algorithms = []Algorithm{RS256}
+	if _, err := v.Verify(token, nil); !errors.Is(err, ErrAlgorithm) {
+		t.Fatalf("not allowed algorithm : expected ErrAlgorithm, got %v", err)
+	}
+	if _, err := NewSigner(RS256, keys.ec, ""); !errors.Is(err, ErrKeyType) {
+		t.Fatalf("signer key mismatch : expected ErrKeyType, got %v", err)
+	}
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	v := NewVerifier(StaticKey(keys.secret))
+	for _, token := range []string{"", "a.b", "a.b.c.d", "!!.e30.", encode([]byte("{")) + ".e30."} {
+		if _, err := v.Verify(token, nil); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%q : expected ErrMalformed, got %v", token, err)
+		}
+	}
+}
+
+func TestVerifyTimeClaims(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name       string
+		claims     Claims
+		requireExp bool
+		err        error
+	}{
+		{"valid", Claims{ExpiresAt: At(now.Add(time.Hour)), IssuedAt: At(now)}, true, nil},
+		{"expired", Claims{ExpiresAt: At(now.Add(-2 * time.Minute))}, true, ErrExpired},
+		{"expired within skew", Claims{ExpiresAt: At(now.Add(-30 * time.Second))}, true, nil},
+		{"expires now plus skew", Claims{ExpiresAt: At(now.Add(-time.Minute))}, true, ErrExpired},
+		{"not yet valid", Claims{ExpiresAt: At(now.Add(time.Hour)), NotBefore: At(now.Add(2 * time.Minute))}, true, ErrNotYetValid},
+		{"not before within skew", Claims{ExpiresAt: At(now.Add(time.Hour)), NotBefore: At(now.Add(30 * time.Second))}, true, nil},
+		{"issued in future", Claims{ExpiresAt: At(now.Add(time.Hour)), IssuedAt: At(now.Add(2 * time.Minute))}, true, ErrIssuedInFuture},
+		{"issued within skew", Claims{ExpiresAt: At(now.Add(time.Hour)), IssuedAt: At(now.Add(30 * time.Second))}, true, nil},
+		{"no exp required", Claims{IssuedAt: At(now)}, true, ErrMissingClaim},
+		{"no exp allowed", Claims{IssuedAt: At(now)}, false, nil},
+	}
+	for _, tt := range tests {
+		v := NewVerifier(StaticKey(keys.secret))
+		v.Now = func() time.Time { return now }
+		v.RequireExp = tt.requireExp
+		_, err := v.Verify(sign(t, HS256, tt.claims), nil)
+		if !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+			t.Errorf("%s : expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestVerifyIssuerAudience(t *testing.T) {
+	claims := NewClaims("auth", "user-1", time.Hour)
+	claims.Audience = Audience{"api", "admin"}
+	token := sign(t, HS256, claims)
+	tests := []struct {
+		issuer, audience string
+		err              error
+	}{
+		{"", "", nil},
+		{"auth", "api", nil},
+		{"auth", "admin", nil},
+		{"other", "", ErrIssuer},
+		{"", "billing", ErrAudience},
+	}
+	for _, tt := range tests {
+		v := NewVerifier(StaticKey(keys.secret))
+		v.Issuer, v.Audience = tt.issuer, tt.audience
+		_, err := v.Verify(token, nil)
+		if !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+			t.Errorf("iss %q aud %q : expected %v, got %v", tt.issuer, tt.audience, tt.err, err)
+		}
+	}
+}
+
+func TestAudienceJSON(t *testing.T) {
+	var c Claims
+	if err := json.Unmarshal([]byte(`{"aud":"api","exp":1.9}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Audience.Contains("api") || c.ExpiresAt != 1 {
+		t.Fatalf("unexpected claims %+v", c)
+	}
+	data, _ := json.Marshal(Audience{"api"})
+	if string(data) != `"api"` {
+		t.Fatalf("single audience is encoded as %s", data)
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	tests := []struct {
+		header string
+		token  string
+		err    error
+	}{
+		{"Bearer abc", "abc", nil},
+		{"bearer  abc ", "abc", nil},
+		{"Basic abc", "", ErrNoToken},
+		{"Bearer ", "", ErrNoToken},
+		{"", "", ErrNoToken},
+	}
+	for _, tt := range tests {
+		r, _ := http.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", tt.header)
+		token, err := FromRequest(r)
+		if token != tt.token || err != tt.err {
+			t.Errorf("%q : got %q, %v", tt.header, token, err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	assist "github.com/nooize/go-assist"
+)
+
+const (
+	// DefaultMinRefresh limits how often the remote key set is fetched, successfully or not.
+	DefaultMinRefresh = 5 * time.Minute
+	// DefaultMaxAge is how long the remote key set is used before it is fetched again.
+	DefaultMaxAge = 24 * time.Hour
+	// DefaultFetchTimeout limits the remote key set request.
+	DefaultFetchTimeout = 10 * time.Second
+)
+
+// KeySet looks up the verification key by the "kid" header, kid is empty when the token has no key ID.
+type KeySet interface {
+	Key(kid string, alg Algorithm) (interface{}, error)
+}
+
+type staticKey struct {
+	key interface{}
+}
+
+func (k staticKey) Key(string, Algorithm) (interface{}, error) {
+	return k.key, nil
+}
+
+// StaticKey returns the key set with the single key used for every token. The key is a []byte secret
+// or any key returned by assist.ParseX509PrivateKey, assist.ParseX509PublicKey and the other crypto helpers.
+func StaticKey(key interface{}) KeySet {
+	return staticKey{key: key}
+}
+
+// JWKS is a JSON Web Key Set document.
+type JWKS struct {
+	Keys []*assist.JWK `json:"keys"`
+}
+
+// ParseJWKS decodes the JSON Web Key Set.
+func ParseJWKS(data []byte) (*JWKS, error) {
+	var set JWKS
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("jwt: fail to parse key set : %s", err.Error())
+	}
+	return &set, nil
+}
+
+// Add appends the key with its ID and algorithm, see assist.NewJWK for supported keys.
+func (s *JWKS) Add(key interface{}, kid string, alg Algorithm) error {
+	j, err := assist.NewJWK(key)
+	if err != nil {
+		return err
+	}
+	j.Kid, j.Alg, j.Use = kid, string(alg), "sig"
+	s.Keys = append(s.Keys, j)
+	return nil
+}
+
+// Public returns the copy of the set with public keys only, ready to be published.
+// Symmetric keys are dropped.
+func (s *JWKS) Public() *JWKS {
+	var set JWKS
+	for _, j := range s.Keys {
+		if j.Kty != "oct" {
+			set.Keys = append(set.Keys, j.Public())
+		}
+	}
+	return &set
+}
+
+// Key returns the key with the ID usable for the algorithm. When kid is empty, the only key
+// of the set is used.
+func (s *JWKS) Key(kid string, alg Algorithm) (interface{}, error) {
+	if kid == "" && len(s.Keys) != 1 {
+		return nil, fmt.Errorf("%w : token has no kid", ErrKeyNotFound)
+	}
+	for _, j := range s.Keys {
+		if kid != "" && j.Kid != kid {
+			continue
+		}
+		if (j.Alg != "" && j.Alg != string(alg)) || (j.Use != "" && j.Use != "sig") {
+			continue
+		}
+		key, err := j.Key()
+		if err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w : kid %q", ErrKeyNotFound, kid)
+}
+
+// RemoteJWKS is the key set fetched from the URL. The set is fetched again when the token has an unknown
+// key ID or the set is older than MaxAge, so rotated keys are picked up without restart. Fetches are not
+// made more often than MinRefresh, failed ones included, and concurrent lookups share one fetch. When
+// the fetch fails, the previously fetched set is still used.
+type RemoteJWKS struct {
+	URL    string
+	Client *http.Client
+	// MinRefresh is the minimal interval between fetches.
+	MinRefresh time.Duration
+	// MaxAge is how long the fetched set is used, zero disables periodic refresh.
+	MaxAge time.Duration
+
+	fetching sync.Mutex
+	mu       sync.Mutex
+	set      *JWKS
+	fetched  time.Time
+	tried    time.Time
+	err      error
+}
+
+func NewRemoteJWKS(url string) *RemoteJWKS {
+	return &RemoteJWKS{
+		URL:        url,
+		Client:     &http.Client{Timeout: DefaultFetchTimeout},
+		MinRefresh: DefaultMinRefresh,
+		MaxAge:     DefaultMaxAge,
+	}
+}
+
+func (r *RemoteJWKS) Key(kid string, alg Algorithm) (interface{}, error) {
+	r.mu.Lock()
+	set, fetched, tried := r.set, r.fetched, r.tried
+	r.mu.Unlock()
+	if set != nil && (r.MaxAge <= 0 || time.Since(fetched) < r.MaxAge) {
+		if key, err := set.Key(kid, alg); err == nil {
+			return key, nil
+		}
+	}
+	fetchErr := r.refresh(tried)
+	r.mu.Lock()
+	set = r.set
+	r.mu.Unlock()
+	if set == nil {
+		return nil, fetchErr
+	}
+	key, err := set.Key(kid, alg)
+	if err != nil && fetchErr != nil {
+		return nil, fetchErr
+	}
+	return key, err
+}
+
+// Refresh fetches the key set.
+func (r *RemoteJWKS) Refresh() error {
+	r.fetching.Lock()
+	defer r.fetching.Unlock()
+	return r.fetch()
+}
+
+// refresh fetches the key set, unless it was tried within MinRefresh or by another lookup after
+// the attempt seen by the caller. Returns the error of the last attempt.
+func (r *RemoteJWKS) refresh(seen time.Time) error {
+	r.fetching.Lock()
+	defer r.fetching.Unlock()
+	r.mu.Lock()
+	tried, err := r.tried, r.err
+	r.mu.Unlock()
+	if !tried.Equal(seen) || (!tried.IsZero() && time.Since(tried) < r.MinRefresh) {
+		return err
+	}
+	return r.fetch()
+}
+
+func (r *RemoteJWKS) fetch() error {
+	set, err := r.get()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tried, r.err = time.Now(), err
+	if err == nil {
+		r.set, r.fetched = set, r.tried
+	}
+	return err
+}
+
+func (r *RemoteJWKS) get() (*JWKS, error) {
+	client := r.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultFetchTimeout}
+	}
+	resp, err := client.Get(r.URL)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: fail to fetch key set : %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwt: fail to fetch key set : %s", resp.Status)
+	}
+	var set JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("jwt: fail to parse key set : %s", err.Error())
+	}
+	return &set, nil
+}
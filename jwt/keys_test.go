@@ -0,0 +1,257 @@
+package jwt
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJWKSKey(t *testing.T) {
+	set := &JWKS{}
+	for _, k := range []struct {
+		key interface{}
+		kid string
+		alg Algorithm
+	}{
+		{&keys.rsa.PublicKey, "rsa", RS256},
+		{&keys.ec.PublicKey, "ec", ES256},
+		{keys.ed.Public(), "shared", EdDSA},
+		{&keys.ec.PublicKey, "shared", ES256},
+	} {
+		if err := set.Add(k.key, k.kid, k.alg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	enc := *set.Keys[1]
+	enc.Kid, enc.Use = "enc", "enc"
+	set.Keys = append(set.Keys, &enc)
+
+	tests := []struct {
+		kid  string
+		alg  Algorithm
+		want interface{}
+	}{
+		{"rsa", RS256, &keys.rsa.PublicKey},
+		{"ec", ES256, &keys.ec.PublicKey},
+		{"shared", EdDSA, keys.ed.Public()},
+		{"shared", ES256, &keys.ec.PublicKey},
+		{"rsa", ES256, nil},
+		{"enc", ES256, nil},
+		{"unknown", RS256, nil},
+		{"", RS256, nil},
+	}
+	for _, tt := range tests {
+		key, err := set.Key(tt.kid, tt.alg)
+		if tt.want == nil {
+			if !errors.Is(err, ErrKeyNotFound) {
+				t.Errorf("%q %s : expected ErrKeyNotFound, got %T %v", tt.kid, tt.alg, key, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q %s : unexpected error : %v", tt.kid, tt.alg, err)
+			continue
+		}
+		if !key.(interface{ Equal(crypto.PublicKey) bool }).Equal(tt.want) {
+			t.Errorf("%q %s : unexpected key %T", tt.kid, tt.alg, key)
+		}
+	}
+
+	single := &JWKS{}
+	if err := single.Add(&keys.rsa.PublicKey, "", RS256); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := single.Key("", RS256); err != nil {
+		t.Fatalf("the only key is not used for token without kid : %v", err)
+	}
+}
+
+func TestJWKSPublic(t *testing.T) {
+	set := &JWKS{}
+	_ = set.Add(keys.secret, "hs", HS256)
+	_ = set.Add(keys.ec, "ec", ES256)
+	data, err := json.Marshal(set.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := ParseJWKS(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(public.Keys) != 1 || public.Keys[0].Kid != "ec" || public.Keys[0].IsPrivate() {
+		t.Fatalf("unexpected public set %s", data)
+	}
+	if _, err = NewVerifier(public).Verify(signKid(t, "ec"), nil); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+}
+
+// keyServer serves the key set and counts requests.
+type keyServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	set     *JWKS
+	status  int
+	fetches int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func newKeyServer(t *testing.T, kid string) *keyServer {
+	s := &keyServer{status: http.StatusOK}
+	s.rotate(t, kid)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.fetches, 1)
+		s.mu.Lock()
+		set, status, started, release := s.set, s.status, s.started, s.release
+		s.mu.Unlock()
+		if started != nil {
+			started <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// rotate publishes the EC key under the kid.
+func (s *keyServer) rotate(t *testing.T, kid string) {
+	set := &JWKS{}
+	if err := set.Add(&keys.ec.PublicKey, kid, ES256); err != nil {
+		t.Fatal(err)
+	}
+	s.mu.Lock()
+	s.set = set
+	s.mu.Unlock()
+}
+
+func (s *keyServer) count() int {
+	return int(atomic.LoadInt32(&s.fetches))
+}
+
+func signKid(t *testing.T, kid string) string {
+	t.Helper()
+	s, err := NewSigner(ES256, keys.ec, kid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := s.Sign(NewClaims("auth", "user-1", time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestRemoteJWKSRefetchesUnknownKid(t *testing.T) {
+	srv := newKeyServer(t, "k1")
+	remote := NewRemoteJWKS(srv.URL)
+	remote.MinRefresh = 0
+	v := NewVerifier(remote)
+
+	for i := 0; i < 3; i++ {
+		if _, err := v.Verify(signKid(t, "k1"), nil); err != nil {
+			t.Fatalf("unexpected error : %v", err)
+		}
+	}
+	if srv.count() != 1 {
+		t.Fatalf("known kid : expected 1 fetch, got %d", srv.count())
+	}
+
+	srv.rotate(t, "k2")
+	if _, err := v.Verify(signKid(t, "k2"), nil); err != nil {
+		t.Fatalf("rotated key : unexpected error : %v", err)
+	}
+	if srv.count() != 2 {
+		t.Fatalf("rotated key : expected 2 fetches, got %d", srv.count())
+	}
+	if _, err := v.Verify(signKid(t, "k3"), nil); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("unknown kid : expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestRemoteJWKSMinRefresh(t *testing.T) {
+	srv := newKeyServer(t, "k1")
+	remote := NewRemoteJWKS(srv.URL)
+	v := NewVerifier(remote)
+
+	for i := 0; i < 3; i++ {
+		if _, err := v.Verify(signKid(t, "unknown"), nil); !errors.Is(err, ErrKeyNotFound) {
+			t.Fatalf("expected ErrKeyNotFound, got %v", err)
+		}
+	}
+	if srv.count() != 1 {
+		t.Fatalf("expected 1 fetch within MinRefresh, got %d", srv.count())
+	}
+
+	// failed fetches are throttled too and the fetched set is still used
+	remote.MinRefresh = 0
+	srv.mu.Lock()
+	srv.status = http.StatusInternalServerError
+	srv.mu.Unlock()
+	if _, err := v.Verify(signKid(t, "unknown"), nil); err == nil || errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected fetch error, got %v", err)
+	}
+	if _, err := v.Verify(signKid(t, "k1"), nil); err != nil {
+		t.Fatalf("fetched set is not used after failed fetch : %v", err)
+	}
+	remote.MinRefresh = time.Hour
+	if _, err := v.Verify(signKid(t, "unknown"), nil); err == nil {
+		t.Fatal("expected fetch error")
+	}
+	if srv.count() != 2 {
+		t.Fatalf("expected 2 fetches, got %d", srv.count())
+	}
+}
+
+func TestRemoteJWKSMaxAge(t *testing.T) {
+	srv := newKeyServer(t, "k1")
+	remote := NewRemoteJWKS(srv.URL)
+	remote.MinRefresh, remote.MaxAge = 0, time.Nanosecond
+	for i := 0; i < 2; i++ {
+		if _, err := remote.Key("k1", ES256); err != nil {
+			t.Fatalf("unexpected error : %v", err)
+		}
+	}
+	if srv.count() != 2 {
+		t.Fatalf("expected stale set to be fetched again, got %d fetches", srv.count())
+	}
+}
+
+func TestRemoteJWKSSharedFetch(t *testing.T) {
+	srv := newKeyServer(t, "k1")
+	srv.started, srv.release = make(chan struct{}), make(chan struct{})
+	remote := NewRemoteJWKS(srv.URL)
+	remote.MinRefresh = 0
+
+	const lookups = 10
+	errs := make(chan error, lookups)
+	for i := 0; i < lookups; i++ {
+		go func() {
+			_, err := remote.Key("k1", ES256)
+			errs <- err
+		}()
+	}
+	<-srv.started
+	// let other lookups queue up behind the fetch in progress
+	time.Sleep(50 * time.Millisecond)
+	srv.mu.Lock()
+	srv.started = nil
+	srv.mu.Unlock()
+	close(srv.release)
+	for i := 0; i < lookups; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("unexpected error : %v", err)
+		}
+	}
+	if srv.count() != 1 {
+		t.Fatalf("expected 1 shared fetch, got %d", srv.count())
+	}
+}
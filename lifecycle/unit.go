@@ -0,0 +1,31 @@
+package lifecycle
+
+import "context"
+
+/*
+ Lifecycle core shared by apx and di
+
+ apx.ApxUnit is an alias of Unit, di.Unit runs a Unit internally:
+ channel based di.UnitHandler is adapted to Unit by di.HandlerToUnit.
+ Both packages start and stop units with the di graph runner, apx chains
+ its units in the order they were added.
+
+ app.Add(dbUnit.Lifecycle())           // di unit under apx
+ web.After(di.FromLifecycle(apxUnit))  // apx unit under di
+*/
+
+type Unit interface {
+	// Start tries to perform the initial initialization of the service, the logic of the function must make sure
+	// that all created connections to remote services are in working order and are pinging. Otherwise, the
+	// application will need additional error handling. The context expires when the start time is over.
+	Start(ctx context.Context) error
+	// IsReady will be called by the service controller at regular intervals, it is important that a response with
+	// any error will be regarded as an unrecoverable state of the service and will lead to an emergency stop of
+	// the application. If the service is not critical for the application, like a memcached, then try to implement
+	// the logic of self-diagnosis and service recovery inside Ping, and return the nil as a response even if the
+	// recovery failed.
+	IsReady(ctx context.Context) error
+	// Stop will be executed when the service controller receives a stop command. Normally, this happens after the
+	// main thread of the application has already finished. That is, no more requests from the outside are expected.
+	Stop() error
+}
@@ -0,0 +1,48 @@
+package assist
+
+import (
+	"os"
+	"sync"
+)
+
+/*
+ Signals taken over from the application shutdown
+
+ release := assist.ReserveSignals(syscall.SIGHUP)
+ defer release()
+*/
+
+var reserved = struct {
+	sync.Mutex
+	signals map[os.Signal]int
+}{signals: make(map[os.Signal]int)}
+
+// ReserveSignals marks the signals as handled elsewhere, e.g. SIGHUP by env.Provider.ReloadOnSignal.
+// apx applications and di services ignore reserved signals instead of shutting down. Reservations
+// are counted, returns the function which releases this one.
+func ReserveSignals(sig ...os.Signal) (release func()) {
+	reserved.Lock()
+	for _, s := range sig {
+		reserved.signals[s]++
+	}
+	reserved.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			reserved.Lock()
+			defer reserved.Unlock()
+			for _, s := range sig {
+				if reserved.signals[s]--; reserved.signals[s] <= 0 {
+					delete(reserved.signals, s)
+				}
+			}
+		})
+	}
+}
+
+// IsSignalReserved reports whether the signal is reserved with ReserveSignals.
+func IsSignalReserved(sig os.Signal) bool {
+	reserved.Lock()
+	defer reserved.Unlock()
+	return reserved.signals[sig] > 0
+}
@@ -0,0 +1,26 @@
+package assist
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestReserveSignals(t *testing.T) {
+	if IsSignalReserved(syscall.SIGHUP) {
+		t.Fatal("SIGHUP is reserved before ReserveSignals")
+	}
+	first := ReserveSignals(syscall.SIGHUP)
+	second := ReserveSignals(syscall.SIGHUP, syscall.SIGQUIT)
+	if !IsSignalReserved(syscall.SIGHUP) || !IsSignalReserved(syscall.SIGQUIT) || IsSignalReserved(syscall.SIGTERM) {
+		t.Fatal("unexpected reserved signals")
+	}
+	second()
+	second()
+	if !IsSignalReserved(syscall.SIGHUP) || IsSignalReserved(syscall.SIGQUIT) {
+		t.Fatal("release affects other reservations")
+	}
+	first()
+	if IsSignalReserved(syscall.SIGHUP) {
+		t.Fatal("SIGHUP is reserved after all releases")
+	}
+}